@@ -0,0 +1,12 @@
+// Command gig-vet runs the gig analyzer, standalone or with go vet -vettool=$(which gig-vet)
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/analyzer"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
@@ -0,0 +1,120 @@
+// Package analyzer exposes gig as a go/analysis Analyzer, so that misgrouped imports are
+// reported by go vet -vettool, multichecker binaries and golangci-lint plugins
+package analyzer
+
+import (
+	"go/token"
+	"io"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/formatter"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+const doc = `check that imports are grouped and sorted
+
+The gig analyzer reports imports that are not grouped into std, third-party,
+organization and project blocks, or not sorted within them, with a suggested
+fix replacing the import block.`
+
+// Analyzer reports misgrouped imports, configured through its flags
+var Analyzer = New(formatter.FormatterConfig{})
+
+// New creates an analyzer with a default configuration, which its flags override
+func New(config formatter.FormatterConfig) *analysis.Analyzer {
+	a := &analysis.Analyzer{
+		Name: "gig",
+		Doc:  doc,
+		URL:  "https://github.com/siyuan-infoblox/go-imports-group",
+	}
+	a.Flags.Var((*listFlag)(&config.Orgs), "orgs", "comma-separated list of organization prefixes")
+	a.Flags.StringVar(&config.CurrentProject, "current-project", config.CurrentProject, "module of the current project, detected from go.mod by default")
+	a.Flags.Var((*listFlag)(&config.Disable), "disable", "comma-separated list of violation codes or rules not reported")
+	a.Run = func(pass *analysis.Pass) (any, error) {
+		return run(pass, config)
+	}
+	return a
+}
+
+func run(pass *analysis.Pass, config formatter.FormatterConfig) (any, error) {
+	config.Output = io.Discard
+	g := formatter.New(config)
+
+	for _, file := range pass.Files {
+		tokFile := pass.Fset.File(file.Pos())
+		if tokFile == nil || !strings.HasSuffix(tokFile.Name(), ".go") {
+			continue
+		}
+		// Read through the pass so that overlays of the driver are honored
+		src, err := pass.ReadFile(tokFile.Name())
+		if err != nil {
+			return nil, err
+		}
+		if len(src) != tokFile.Size() {
+			// The file changed since it was parsed, positions would not match
+			continue
+		}
+
+		record, err := g.CheckSource(tokFile.Name(), src)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, violation := range record.Violations {
+			diagnostic := analysis.Diagnostic{
+				Pos:      linePos(tokFile, violation.Line, violation.Column),
+				Category: violation.Code,
+				Message:  violation.Code + " " + violation.Message,
+			}
+			// The fix replaces the whole import block, it is attached once per file
+			if i == 0 && record.Fix != nil {
+				diagnostic.SuggestedFixes = []analysis.SuggestedFix{newSuggestedFix(tokFile, record.Fix)}
+			}
+			pass.Report(diagnostic)
+		}
+	}
+	return nil, nil
+}
+
+// newSuggestedFix converts the fix of a file into a text edit of the lines of its import block
+func newSuggestedFix(tokFile *token.File, fix *report.Fix) analysis.SuggestedFix {
+	end := token.Pos(tokFile.Base() + tokFile.Size())
+	if fix.EndLine < tokFile.LineCount() {
+		end = tokFile.LineStart(fix.EndLine+1) - 1 // keep the line break
+	}
+	return analysis.SuggestedFix{
+		Message: "Group and sort imports",
+		TextEdits: []analysis.TextEdit{{
+			Pos:     tokFile.LineStart(fix.StartLine),
+			End:     end,
+			NewText: []byte(fix.Replacement),
+		}},
+	}
+}
+
+// linePos converts a 1-based line and column into a position of the file
+func linePos(tokFile *token.File, line, column int) token.Pos {
+	if column < 1 {
+		column = 1
+	}
+	return tokFile.LineStart(line) + token.Pos(column-1)
+}
+
+// listFlag is a comma-separated list flag
+type listFlag []string
+
+func (f *listFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *listFlag) Set(value string) error {
+	*f = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*f = append(*f, item)
+		}
+	}
+	return nil
+}
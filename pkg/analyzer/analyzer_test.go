@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/formatter"
+)
+
+func TestAnalyzer(t *testing.T) {
+	a := New(formatter.FormatterConfig{
+		Orgs:           []string{"example.org"},
+		CurrentProject: "example.com/project",
+	})
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "example.com/project/a")
+}
+
+func TestAnalyzer_Flags(t *testing.T) {
+	a := New(formatter.FormatterConfig{})
+	for flag, value := range map[string]string{
+		"current-project": "example.com/project",
+		"disable":         "GIG004",
+	} {
+		if err := a.Flags.Set(flag, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	analysistest.Run(t, analysistest.TestData(), a, "example.com/project/disabled")
+}
+
+func TestAnalyzer_ReadFile(t *testing.T) {
+	// The file only exists in an overlay of the driver, not on disk
+	const name = "/overlay/example.com/project/c/c.go"
+	src := []byte("package c\n\nimport (\n\t\"os\"\n\t\"fmt\"\n)\n\nvar _ = fmt.Sprint(os.Args)\n")
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var diagnostics []analysis.Diagnostic
+	pass := &analysis.Pass{
+		Fset:  fset,
+		Files: []*ast.File{file},
+		ReadFile: func(filename string) ([]byte, error) {
+			if filename != name {
+				return nil, os.ErrNotExist
+			}
+			return src, nil
+		},
+		Report: func(d analysis.Diagnostic) { diagnostics = append(diagnostics, d) },
+	}
+	if _, err := run(pass, formatter.FormatterConfig{CurrentProject: "example.com/project"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Category != "GIG004" {
+		t.Fatalf("expected a GIG004 diagnostic, got %v", diagnostics)
+	}
+}
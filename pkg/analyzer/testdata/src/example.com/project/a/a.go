@@ -0,0 +1,13 @@
+package a
+
+import (
+	"os"
+	"fmt" // want `GIG004 imports are not sorted within the std group`
+	"example.com/lib" // want `GIG002 missing blank line between std and third-party imports`
+
+	"example.com/project/b"
+
+	"example.org/kit" // want `GIG003 org:example.org/kit imports should come before project imports`
+)
+
+var _ = fmt.Sprint(os.Args, lib.Name, b.Name, kit.Name)
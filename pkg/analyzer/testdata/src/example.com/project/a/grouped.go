@@ -0,0 +1,14 @@
+package a
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/lib"
+
+	"example.org/kit"
+
+	"example.com/project/b"
+)
+
+var _ = fmt.Sprint(strings.ToUpper(lib.Name), b.Name, kit.Name)
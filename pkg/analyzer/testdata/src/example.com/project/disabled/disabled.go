@@ -0,0 +1,8 @@
+package disabled
+
+import (
+	"strings"
+	"fmt"
+)
+
+var _ = fmt.Sprint(strings.ToUpper("disabled"))
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/config"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/formatter"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/hook"
+)
+
+var (
+	hookChain     bool
+	hookFramework bool
+	hookNoStage   bool
+)
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Run gig as a git pre-commit hook",
+	Long: `Install, uninstall or run the git pre-commit hook running gig on the staged Go files.
+
+In fix mode, the default, the imports of the staged files are grouped and the
+files are staged again. Files that also have unstaged changes are only checked,
+since staging them would commit changes that were not staged.
+In check mode the commit fails when any staged file has misgrouped imports.
+The mode is set with --check or with the hook.mode key of the configuration file.`,
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the pre-commit hook in the current git repository",
+	Long: `Install the pre-commit hook in the current git repository.
+
+An existing pre-commit hook that was not installed by gig is left untouched
+unless --chain is given, which keeps it and runs it before gig.
+With --framework, the hook is added to the .pre-commit-config.yaml file of the
+pre-commit framework instead.`,
+	Args:         cobra.NoArgs,
+	RunE:         runHookInstall,
+	SilenceUsage: true,
+}
+
+var hookUninstallCmd = &cobra.Command{
+	Use:          "uninstall",
+	Short:        "Remove the pre-commit hook installed by gig, restoring a chained hook",
+	Args:         cobra.NoArgs,
+	RunE:         runHookUninstall,
+	SilenceUsage: true,
+}
+
+var hookRunCmd = &cobra.Command{
+	Use:   "run [FILE...]",
+	Short: "Run gig on the staged Go files, as the pre-commit hook does",
+	Long: `Run gig on the staged Go files, as the pre-commit hook does.
+
+The files given as arguments are processed instead of the staged files, as
+passed by the pre-commit framework.`,
+	RunE:         runHookRun,
+	SilenceUsage: true,
+}
+
+func init() {
+	hookInstallCmd.Flags().BoolVar(&hookChain, "chain", false, "Keep an existing pre-commit hook and run it before gig")
+	hookInstallCmd.Flags().BoolVar(&hookFramework, "framework", false, "Add the hook to the pre-commit framework configuration instead of .git/hooks")
+	hookUninstallCmd.Flags().BoolVar(&hookFramework, "framework", false, "Remove the hook from the pre-commit framework configuration instead of .git/hooks")
+	hookRunCmd.Flags().BoolVar(&hookNoStage, "no-stage", false, "Do not stage the formatted files again")
+
+	hookCmd.AddCommand(hookInstallCmd, hookUninstallCmd, hookRunCmd)
+	rootCmd.AddCommand(hookCmd)
+}
+
+func runHookInstall(cmd *cobra.Command, args []string) error {
+	if hookFramework {
+		path, err := hook.InstallFramework(".")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), errors.InfoMsgFrameworkHookAdded+"\n", path)
+		return nil
+	}
+
+	result, err := hook.Install(".", hookChain)
+	if err != nil {
+		return err
+	}
+	if result.ChainedPath != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), errors.InfoMsgHookChained+"\n", result.ChainedPath)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), errors.InfoMsgHookInstalled+"\n", result.Path)
+	return nil
+}
+
+func runHookUninstall(cmd *cobra.Command, args []string) error {
+	if hookFramework {
+		path, err := hook.UninstallFramework(".")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), errors.InfoMsgFrameworkHookRemoved+"\n", path)
+		return nil
+	}
+
+	result, err := hook.Uninstall(".")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), errors.InfoMsgHookUninstalled+"\n", result.Path)
+	if result.Restored {
+		fmt.Fprintf(cmd.OutOrStdout(), errors.InfoMsgHookRestored+"\n", result.Path)
+	}
+	return nil
+}
+
+func runHookRun(cmd *cobra.Command, args []string) error {
+	if err := validateDisable(); err != nil {
+		return err
+	}
+	cfg, err := loadConfig(cmd, ".")
+	if err != nil {
+		return err
+	}
+
+	hookCheck := check
+	if !cmd.Flags().Changed("check") {
+		hookCheck = cfg.Hook.Mode == config.HookModeCheck
+	}
+
+	return hook.Run(".", formatter.FormatterConfig{
+		Orgs:           orgs,
+		CurrentProject: currentProject,
+		Check:          hookCheck,
+		Disable:        append(cfg.Disable, disable...),
+		Aliases:        cfg.Aliases,
+		Rules:          cfg.Rules,
+		DotImports:     cfg.DotImports,
+		BlankImports:   cfg.BlankImports,
+		Sort:           cfg.Sort,
+	}, hook.RunOptions{Files: args, NoStage: hookNoStage, Output: cmd.OutOrStdout()})
+}
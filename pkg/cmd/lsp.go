@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/lsp"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run a language server over stdio grouping imports on format and organize imports",
+	Long: `Run a minimal Language Server Protocol server over stdin and stdout.
+
+The server supports textDocument/formatting and the source.organizeImports code
+action, both replacing the import block of the document with the grouped imports.
+Settings are resolved per workspace folder from the .gig.yaml file and go.mod, and
+can be overridden by --orgs, --current-project or the initialization options
+{"orgs": [...], "currentProject": "..."}.`,
+	Args:         cobra.NoArgs,
+	RunE:         runLSP,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
+
+func runLSP(cmd *cobra.Command, args []string) error {
+	return lsp.NewServer(os.Stdin, os.Stdout, versionStr, lsp.Options{
+		Orgs:           orgs,
+		CurrentProject: currentProject,
+	}).Serve()
+}
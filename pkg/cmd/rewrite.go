@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/formatter"
+)
+
+var (
+	rewriteFrom    []string
+	rewriteTo      []string
+	rewriteMapping string
+	rewrites       []formatter.Rewrite
+)
+
+var rewriteCmd = &cobra.Command{
+	Use:   "rewrite PATH",
+	Short: "Rewrite import paths, e.g. when a module moves",
+	Long: `Rewrite import paths in a Go file or directory, e.g. when a module moves.
+
+Each --from path is replaced by the --to path given at the same position. Paths
+ending with /... rewrite the subpackages too:
+
+  gig rewrite --from github.com/old-org/lib/... --to github.com/acme/lib/v2/... --in-place .
+
+Rewrites can also be read from a mapping file holding one "FROM TO" pair per line.
+Rewritten imports are moved to their group, and aliased to their previous name when
+the new package has another one so that the file keeps compiling. Only the files
+importing a rewritten path are modified, and --check reports them instead.`,
+	Args:         validateArgs,
+	RunE:         runRewrite,
+	SilenceUsage: true,
+}
+
+func init() {
+	rewriteCmd.Flags().StringArrayVar(&rewriteFrom, "from", []string{}, "Import path to rewrite, ending with /... to include subpackages (repeatable)")
+	rewriteCmd.Flags().StringArrayVar(&rewriteTo, "to", []string{}, "Import path replacing the --from path at the same position (repeatable)")
+	rewriteCmd.Flags().StringVar(&rewriteMapping, "mapping", "", "File of rewrites, one \"FROM TO\" pair per line")
+	rootCmd.AddCommand(rewriteCmd)
+}
+
+func runRewrite(cmd *cobra.Command, args []string) error {
+	if len(rewriteFrom) != len(rewriteTo) {
+		return &errors.ConfigError{Key: "to", Err: fmt.Errorf(errors.ErrMsgRewriteMismatch)}
+	}
+	for i := range rewriteFrom {
+		rewrite, err := formatter.ParseRewrite(rewriteFrom[i], rewriteTo[i])
+		if err != nil {
+			return err
+		}
+		rewrites = append(rewrites, rewrite)
+	}
+	if rewriteMapping != "" {
+		mapped, err := formatter.ReadRewrites(rewriteMapping)
+		if err != nil {
+			return err
+		}
+		rewrites = append(rewrites, mapped...)
+	}
+	if len(rewrites) == 0 {
+		return &errors.ConfigError{Key: "from", Err: fmt.Errorf(errors.ErrMsgNoRewrites)}
+	}
+	return run(cmd, args)
+}
@@ -1,13 +1,25 @@
 package cmd
 
 import (
+	stderrors "errors"
 	"fmt"
+	"go/build"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 
+	"github.com/siyuan-infoblox/go-imports-group/pkg/config"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/daemon"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
 	"github.com/siyuan-infoblox/go-imports-group/pkg/formatter"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/journal"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/utils"
 )
 
+// defaultBackupSuffix is the suffix of the backups when --backup is given without a value
+const defaultBackupSuffix = ".orig"
+
 const (
 	UseDescription   = "gig [flags] PATH"
 	ShortDescription = "Go imports grouper - A tool to group and sort Go imports"
@@ -23,15 +35,52 @@ Organization packages can be further subdivided by project.
 
 PATH can be either a single Go file or a directory. When a directory is specified,
 all Go source files (excluding test files) in the directory and subdirectories
-will be processed recursively.`
+will be processed recursively.
+
+With --staged or --changed-since, only the Go files that are staged in the git
+index or changed since the given git ref, including untracked files that are not
+ignored, are processed. PATH is optional in these modes and defaults to the
+current directory.
+
+With --check, files are left untouched and every misgrouped import is reported.
+The command exits with a non-zero status when any file needs changes.
+Each violation has a stable code (e.g., GIG002) that can be disabled with
+--disable, in the configuration file, or for a single import with a
+//gig:ignore [CODE...] comment.
+
+Settings shared by a team can be stored in a .gig.yaml file, looked up from
+PATH upwards. Command line flags take precedence over it.
+
+Runs with --in-place are journaled, and the last one can be reverted with gig undo.`
 )
 
 var (
-	orgs           []string
-	currentProject string
-	inPlace        bool
-	showVersion    bool
-	versionStr     string
+	orgs              []string
+	currentProject    string
+	inPlace           bool
+	showVersion       bool
+	changedSince      string
+	staged            bool
+	lines             []string
+	goos              string
+	goarch            string
+	buildTags         []string
+	followSymlinks    bool
+	outputFormat      string
+	check             bool
+	disable           []string
+	configPath        string
+	useDaemon         bool
+	socketPath        string
+	backupSuffix      string
+	noJournal         bool
+	removeUnused      bool
+	addMissing        bool
+	removeAliases     bool
+	consistentAliases string
+	replaceDenied     bool
+	migrateDeprecated bool
+	versionStr        string
 )
 
 var rootCmd = &cobra.Command{
@@ -47,6 +96,28 @@ func init() {
 	rootCmd.PersistentFlags().StringSliceVar(&orgs, "orgs", []string{}, "Comma-separated list of organization prefixes (e.g., github.com/myorg,github.com/acme-corp)")
 	rootCmd.PersistentFlags().StringVar(&currentProject, "current-project", "", "Name of the current project (e.g., github.com/username/go-imports-group)")
 	rootCmd.PersistentFlags().BoolVar(&inPlace, "in-place", false, "Modify the file in place instead of printing to stdout")
+	rootCmd.PersistentFlags().StringVar(&changedSince, "changed-since", "", "Only process Go files changed since the given git ref (e.g., origin/main), including untracked files")
+	rootCmd.PersistentFlags().BoolVar(&staged, "staged", false, "Only process Go files staged in the git index")
+	rootCmd.PersistentFlags().StringArrayVar(&lines, "lines", []string{}, "Only rewrite the file if its import declaration intersects the line range start:end (repeatable, requires a single Go file)")
+	rootCmd.PersistentFlags().StringVar(&goos, "goos", "", "Only process files matching this GOOS (file suffixes and build constraints)")
+	rootCmd.PersistentFlags().StringVar(&goarch, "goarch", "", "Only process files matching this GOARCH (file suffixes and build constraints)")
+	rootCmd.PersistentFlags().StringSliceVar(&buildTags, "tags", []string{}, "Comma-separated list of build tags files must satisfy (e.g., integration,cgo)")
+	rootCmd.PersistentFlags().BoolVar(&followSymlinks, "follow-symlinks", false, "Follow symlinked directories when walking a directory, processing each file once")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "format", string(report.FormatText), "Output format: text, json (single document), ndjson (one record per line), or sarif, github and checkstyle (require --check)")
+	rootCmd.PersistentFlags().BoolVar(&check, "check", false, "Report misgrouped imports without modifying files, exiting with a non-zero status when any file needs changes")
+	rootCmd.PersistentFlags().StringSliceVar(&disable, "disable", []string{}, "Comma-separated list of violation codes or rules not reported in check mode (e.g., GIG002,unsorted)")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Path of the configuration file, looked up as "+config.FileName+" from PATH upwards by default")
+	rootCmd.PersistentFlags().BoolVar(&useDaemon, "daemon", false, "Send the run to the daemon started by 'gig serve', running locally when it is not available")
+	rootCmd.PersistentFlags().StringVar(&socketPath, "socket", daemon.DefaultSocketPath(), "Unix socket of the daemon")
+	rootCmd.PersistentFlags().BoolVar(&removeUnused, "remove-unused", false, "Remove the imports that are not referenced by the file before grouping")
+	rootCmd.PersistentFlags().BoolVar(&removeAliases, "remove-aliases", false, "Remove the aliases equal to the name of the imported package, e.g. fmt \"fmt\"")
+	rootCmd.PersistentFlags().StringVar(&consistentAliases, "consistent-aliases", "", "Rename the imports named differently than in most files of their package or module: package or module")
+	rootCmd.PersistentFlags().BoolVar(&replaceDenied, "replace-denied", false, "Rewrite the imports denied by the rules of the configuration file to their replacement")
+	rootCmd.PersistentFlags().BoolVar(&migrateDeprecated, "migrate-deprecated", false, "Replace the deprecated symbols of the standard library, such as ioutil.ReadFile, by their replacement available in the go directive of the module")
+	rootCmd.PersistentFlags().BoolVar(&addMissing, "add-missing", false, "Add the imports of unresolved package qualifiers found in the standard library, the current module or its dependencies in GOMODCACHE")
+	rootCmd.PersistentFlags().StringVar(&backupSuffix, "backup", "", "Keep the original of each file modified in place next to it, with the given suffix (default .orig)")
+	rootCmd.PersistentFlags().Lookup("backup").NoOptDefVal = defaultBackupSuffix
+	rootCmd.PersistentFlags().BoolVar(&noJournal, "no-journal", false, "Do not journal in-place runs, they cannot be undone with 'gig undo'")
 	rootCmd.PersistentFlags().BoolVarP(&showVersion, "version", "v", false, "Show version information")
 }
 
@@ -55,7 +126,32 @@ func validateArgs(cmd *cobra.Command, args []string) error {
 	if showVersion {
 		return nil
 	}
-	return cobra.ExactArgs(1)(cmd, args)
+	if staged && changedSince != "" {
+		return &errors.ConfigError{Key: "staged", Err: fmt.Errorf(errors.ErrMsgChangedSinceAndStaged)}
+	}
+	if check && inPlace {
+		return &errors.ConfigError{Key: "check", Err: fmt.Errorf(errors.ErrMsgCheckAndInPlace)}
+	}
+	if backupSuffix != "" && !inPlace {
+		return &errors.ConfigError{Key: "backup", Err: fmt.Errorf(errors.ErrMsgBackupNeedsInPlace)}
+	}
+	// Line numbers are those of a single file, they are meaningless for the other files of a run
+	if len(lines) > 0 && (staged || changedSince != "") {
+		return &errors.ConfigError{Key: "lines", Err: fmt.Errorf(errors.ErrMsgLinesNeedsFile)}
+	}
+	// In git mode the path defaults to the current directory
+	if staged || changedSince != "" {
+		return cobra.MaximumNArgs(1)(cmd, args)
+	}
+	if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+		return err
+	}
+	if len(lines) > 0 {
+		if isDir, err := utils.IsDirectory(args[0]); err == nil && isDir {
+			return &errors.ConfigError{Key: "lines", Err: fmt.Errorf(errors.ErrMsgLinesNeedsFile)}
+		}
+	}
+	return nil
 }
 
 func run(cmd *cobra.Command, args []string) error {
@@ -65,17 +161,201 @@ func run(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	path := args[0]
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	format, err := report.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	if format.RequiresCheck() && !check {
+		return &errors.ConfigError{Key: "format", Err: fmt.Errorf("%s: %s", errors.ErrMsgFormatNeedsCheck, format)}
+	}
+
+	if err := validateDisable(); err != nil {
+		return err
+	}
+	aliasScope, err := formatter.ParseAliasScope(consistentAliases)
+	if err != nil {
+		return err
+	}
+
+	if useDaemon && configPath == "" && !usesLocalOnlyFlags(cmd) {
+		if client, err := daemon.Dial(socketPath); err == nil {
+			defer client.Close()
+			return runWithDaemon(cmd, client, path, format)
+		}
+	}
+
+	cfg, err := loadConfig(cmd, path)
+	if err != nil {
+		return err
+	}
+
+	var lineRanges []formatter.LineRange
+	for _, value := range lines {
+		lineRange, err := formatter.ParseLineRange(value)
+		if err != nil {
+			return err
+		}
+		lineRanges = append(lineRanges, lineRange)
+	}
+
+	// Only evaluate build constraints when asked to, all files are processed otherwise
+	var buildContext *build.Context
+	if goos != "" || goarch != "" || len(buildTags) > 0 {
+		buildContext = utils.NewBuildContext(goos, goarch, buildTags)
+	}
+
+	// In-place runs are journaled so that they can be undone with gig undo, when possible
+	var recorder *journal.Recorder
+	if inPlace && !noJournal {
+		journalDir, err := journal.DefaultDir()
+		if err != nil {
+			cmd.PrintErrf(errors.WarnMsgJournalFailed+"\n", err)
+		} else {
+			recorder = journal.NewRecorder(journalDir)
+			defer recorder.Close()
+		}
+	}
 
 	g := formatter.New(formatter.FormatterConfig{
-		FilePath:       path, // This will be updated for each file when processing directories
-		Orgs:           orgs,
-		CurrentProject: currentProject,
-		InPlace:        inPlace,
+		FilePath:          path, // This will be updated for each file when processing directories
+		Orgs:              orgs,
+		CurrentProject:    currentProject,
+		InPlace:           inPlace,
+		ChangedSince:      changedSince,
+		Staged:            staged,
+		LineRanges:        lineRanges,
+		BuildContext:      buildContext,
+		FollowSymlinks:    followSymlinks,
+		Format:            format,
+		Check:             check,
+		Disable:           append(cfg.Disable, disable...),
+		Aliases:           cfg.Aliases,
+		Rules:             cfg.Rules,
+		DotImports:        cfg.DotImports,
+		BlankImports:      cfg.BlankImports,
+		Sort:              cfg.Sort,
+		RemoveUnused:      removeUnused,
+		AddMissing:        addMissing,
+		RemoveAliases:     removeAliases,
+		ConsistentAliases: aliasScope,
+		ReplaceDenied:     replaceDenied,
+		Rewrites:          rewrites,
+		MigrateDeprecated: migrateDeprecated,
+		BackupSuffix:      backupSuffix,
+		Journal:           recorder,
 	})
 	return g.ProcessPath(path)
 }
 
+// usesLocalOnlyFlags checks if flags that the daemon does not support are set
+func usesLocalOnlyFlags(cmd *cobra.Command) bool {
+	for _, name := range []string{"changed-since", "staged", "lines", "goos", "goarch", "tags", "follow-symlinks", "from", "mapping"} {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// runWithDaemon sends the run to the daemon and prints what it would have printed
+func runWithDaemon(cmd *cobra.Command, client *daemon.Client, path string, format report.Format) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	args := daemon.Args{
+		Path:              absPath,
+		Disable:           disable,
+		InPlace:           inPlace,
+		RemoveUnused:      removeUnused,
+		AddMissing:        addMissing,
+		RemoveAliases:     removeAliases,
+		ConsistentAliases: consistentAliases,
+		ReplaceDenied:     replaceDenied,
+		MigrateDeprecated: migrateDeprecated,
+		BackupSuffix:      backupSuffix,
+		Format:            string(format),
+	}
+	if inPlace && !noJournal {
+		if args.JournalDir, err = journal.DefaultDir(); err != nil {
+			cmd.PrintErrf(errors.WarnMsgJournalFailed+"\n", err)
+		}
+	}
+	if cmd.Flags().Changed("orgs") {
+		args.Orgs = orgs
+	}
+	if cmd.Flags().Changed("current-project") {
+		args.CurrentProject = currentProject
+	}
+
+	var reply *daemon.Reply
+	if check {
+		reply, err = client.Check(args)
+	} else {
+		reply, err = client.Format(args)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(cmd.OutOrStdout(), reply.Output)
+	if reply.Error != "" {
+		return stderrors.New(reply.Error)
+	}
+	return nil
+}
+
+// validateDisable checks the violation rules given by --disable
+func validateDisable() error {
+	for _, name := range disable {
+		if _, ok := report.LookupRule(name); !ok {
+			return &errors.ConfigError{Key: "disable", Err: fmt.Errorf("%s: %q", errors.ErrMsgUnknownRule, name)}
+		}
+	}
+	return nil
+}
+
+// loadConfig loads the configuration file given by --config or found from path upwards,
+// and applies its settings to the flags that are not set. An empty configuration is
+// returned when there is none.
+func loadConfig(cmd *cobra.Command, path string) (*config.Config, error) {
+	cfg := &config.Config{}
+	if configPath != "" {
+		loaded, err := config.Load(configPath)
+		if err != nil {
+			return nil, err
+		}
+		cfg = loaded
+	} else {
+		found, err := config.Find(path)
+		if err != nil {
+			return nil, err
+		}
+		if found != "" {
+			loaded, err := config.Load(found)
+			if err != nil {
+				return nil, err
+			}
+			cfg = loaded
+		}
+	}
+
+	// Flags take precedence over the configuration file
+	if !cmd.Flags().Changed("orgs") {
+		orgs = cfg.Orgs
+	}
+	if !cmd.Flags().Changed("current-project") {
+		currentProject = cfg.CurrentProject
+	}
+	return cfg, nil
+}
+
 func Execute(version string) error {
 	versionStr = version
 	return rootCmd.Execute()
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/daemon"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a daemon serving gig over a JSON-RPC Unix socket",
+	Long: `Run a long-running daemon exposing the Gig.Format, Gig.Check and Gig.Explain
+JSON-RPC methods over a Unix socket.
+
+Configuration files and go.mod lookups are cached by the daemon and invalidated
+when the files change. Run gig with --daemon to use it transparently.`,
+	Args:         cobra.NoArgs,
+	RunE:         runServe,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	server, err := daemon.Listen(socketPath)
+	if err != nil {
+		return err
+	}
+
+	// Remove the socket when interrupted
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		server.Close()
+	}()
+
+	return server.Serve()
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/journal"
+)
+
+var undoList bool
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [RUN]",
+	Short: "Restore the files modified by an in-place run",
+	Long: `Restore the files modified by the last in-place run, or by the given run.
+
+Runs with --in-place record the original content of every file they modify in a
+journal under the user cache directory ($` + journal.CacheDirEnv + ` when set). Files
+edited after the run are not overwritten, and backups left by --backup are removed
+once their file is restored. Use --list to show the journaled runs.`,
+	Args:         cobra.MaximumNArgs(1),
+	RunE:         runUndo,
+	SilenceUsage: true,
+}
+
+func init() {
+	undoCmd.Flags().BoolVar(&undoList, "list", false, "List the journaled runs, the most recent first")
+	rootCmd.AddCommand(undoCmd)
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	dir, err := journal.DefaultDir()
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if undoList {
+		runs, err := journal.List(dir)
+		if err != nil {
+			return err
+		}
+		for _, run := range runs {
+			fmt.Fprintf(out, errors.InfoMsgRun+"\n", run.ID, run.Time.Local().Format(time.DateTime), len(run.Entries))
+		}
+		return nil
+	}
+
+	var run *journal.Run
+	if len(args) > 0 {
+		if run, err = journal.Load(dir, args[0]); err != nil {
+			return err
+		}
+	} else {
+		runs, err := journal.List(dir)
+		if err != nil {
+			return err
+		}
+		if len(runs) == 0 {
+			return fmt.Errorf(errors.ErrMsgNoRunToUndo)
+		}
+		run = runs[0]
+	}
+
+	result, err := journal.Undo(dir, run)
+	for _, path := range result.Restored {
+		fmt.Fprintf(out, errors.InfoMsgRestoredFile+"\n", path)
+	}
+	for _, path := range result.Unchanged {
+		fmt.Fprintf(out, errors.InfoMsgAlreadyRestoredFile+"\n", path)
+	}
+	for _, path := range result.Missing {
+		fmt.Fprintf(out, errors.InfoMsgMissingFile+"\n", path)
+	}
+	for _, path := range result.Edited {
+		fmt.Fprintf(out, errors.InfoMsgEditedFile+"\n", path)
+	}
+	return err
+}
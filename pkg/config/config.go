@@ -0,0 +1,441 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/utils"
+)
+
+// FileName is the name of the configuration file, looked up from the processed path upwards
+const FileName = ".gig.yaml"
+
+// Modes of the pre-commit hook
+const (
+	HookModeFix   = "fix"   // group the imports of the staged files and stage them again
+	HookModeCheck = "check" // fail the commit when staged files have misgrouped imports
+)
+
+// Config holds the settings shared by a team, command line flags take precedence over them
+type Config struct {
+	Path string `yaml:"-"` // path of the loaded configuration file
+
+	Orgs           []string `yaml:"orgs"`            // organization prefixes to group imports by
+	CurrentProject string   `yaml:"current-project"` // current project override
+	Disable        []string `yaml:"disable"`         // codes or IDs of the violation rules not reported in check mode
+	Hook           Hook     `yaml:"hook"`            // settings of gig hook run
+	Aliases        []Alias  `yaml:"aliases"`         // aliases required for import paths, the first matching one applies
+	Rules          []Rule   `yaml:"rules"`           // imports denied or allowed in the files matching patterns
+
+	// Sort keys of the imports of each group, by group name: std, third-party, project,
+	// org for every organization or org:PREFIX for one of them, and default for the others
+	Sort map[string][]string `yaml:"sort"`
+
+	DotImports   ImportPolicy `yaml:"dot-imports"`   // where dot imports are allowed
+	BlankImports ImportPolicy `yaml:"blank-imports"` // where blank imports are allowed
+}
+
+// Alias requires the imports matching a path pattern to be named after an alias
+type Alias struct {
+	Path  string `yaml:"path"`  // import path, or a path.Match pattern such as k8s.io/api/*/v1
+	Alias string `yaml:"alias"` // required name of the imports
+}
+
+// Hook holds the settings of the pre-commit hook
+type Hook struct {
+	Mode string `yaml:"mode"` // HookModeFix or HookModeCheck, fix when empty
+}
+
+// Keys sorting the imports within a group, applied in order until two imports differ.
+// Imports equal on every key are sorted by path.
+const (
+	SortPath            = "path"             // by import path, byte-wise
+	SortNatural         = "natural"          // by import path, comparing digit runs as numbers so that /v2 comes before /v10
+	SortCaseInsensitive = "case-insensitive" // by import path, ignoring case
+	SortAlias           = "alias"            // by the name the file refers to the import by
+	SortAliasedLast     = "aliased-last"     // imports without alias first
+)
+
+// SortKeys lists the valid sort keys
+var SortKeys = []string{SortPath, SortNatural, SortCaseInsensitive, SortAlias, SortAliasedLast}
+
+// Names of the groups whose sort keys are configured, besides org:PREFIX
+const (
+	SortGroupStd        = "std"
+	SortGroupThirdParty = "third-party"
+	SortGroupProject    = "project"
+	SortGroupOrg        = "org"     // every organization group without its own keys
+	SortGroupDefault    = "default" // every group without its own keys
+)
+
+// Policies of dot and blank imports
+const (
+	PolicyAllow        = "allow"          // allowed in every file
+	PolicyForbid       = "forbid"         // only allowed for the listed paths and in the listed files
+	PolicyAllowInTests = "allow-in-tests" // also allowed in test files
+	PolicyAllowInMain  = "allow-in-main"  // also allowed in main packages
+)
+
+// ImportPolicy restricts where dot or blank imports are allowed
+type ImportPolicy struct {
+	Root   string   `yaml:"-"`      // directory the file patterns are relative to, the one of the configuration file
+	Policy string   `yaml:"policy"` // one of the Policy constants, allow when empty
+	Allow  []string `yaml:"allow"`  // import path patterns allowed anywhere
+	Files  []string `yaml:"files"`  // file patterns allowing them anywhere, e.g. **/init.go
+}
+
+// Allows checks if a policy allows an import path in a file of a package
+func (p ImportPolicy) Allows(importPath string, isStd bool, filePath, packageName string) bool {
+	switch {
+	case p.Policy == "" || p.Policy == PolicyAllow:
+		return true
+	case p.Policy == PolicyAllowInTests && strings.HasSuffix(filePath, "_test.go"):
+		return true
+	case p.Policy == PolicyAllowInMain && packageName == "main":
+		return true
+	}
+	for _, pattern := range p.Allow {
+		if MatchImport(pattern, importPath, isStd) {
+			return true
+		}
+	}
+	return len(p.Files) > 0 && Rule{Root: p.Root, Files: p.Files}.MatchFile(filePath)
+}
+
+// StdPattern matches the packages of the standard library in import path patterns
+const StdPattern = "$std"
+
+// Rule restricts the imports of the files matching its patterns
+type Rule struct {
+	Root  string   `yaml:"-"`     // directory the file patterns are relative to, the one of the configuration file
+	Files []string `yaml:"files"` // file patterns such as cmd/** or *_test.go, all files when empty; patterns starting with ! exclude files
+	Deny  []Deny   `yaml:"deny"`  // denied imports
+	Allow []string `yaml:"allow"` // import path patterns, when not empty the only imports allowed
+}
+
+// Deny denies the imports matching a path pattern
+type Deny struct {
+	Path        string `yaml:"path"`        // import path pattern, ending with /... to include subpackages
+	Message     string `yaml:"message"`     // explanation reported with the violation
+	Replacement string `yaml:"replacement"` // import path suggested instead
+}
+
+// Find looks for the configuration file in the directory of path and its parents.
+// It returns an empty path when there is none.
+func Find(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	dir := absPath
+	if info, err := os.Stat(absPath); err == nil && !info.IsDir() {
+		dir = filepath.Dir(absPath)
+	}
+	for {
+		candidate := filepath.Join(dir, FileName)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Load reads and validates a configuration file
+func Load(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &errors.ConfigError{Path: path, Err: err}
+	}
+
+	config := &Config{Path: path}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && err != io.EOF {
+		return nil, newDecodeError(path, err)
+	}
+
+	// The document is decoded a second time as nodes to know the position of invalid values
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, newDecodeError(path, err)
+	}
+	if err := config.validate(&root); err != nil {
+		return nil, err
+	}
+	for i := range config.Rules {
+		config.Rules[i].Root = filepath.Dir(path)
+	}
+	config.DotImports.Root = filepath.Dir(path)
+	config.BlankImports.Root = filepath.Dir(path)
+	return config, nil
+}
+
+// validate checks the values that cannot be checked by decoding
+func (c *Config) validate(root *yaml.Node) error {
+	for i, name := range c.Disable {
+		if _, ok := report.LookupRule(name); !ok {
+			return &errors.ConfigError{
+				Path: c.Path,
+				Pos:  c.position(valueNode(root, "disable", i)),
+				Key:  "disable",
+				Err:  fmt.Errorf("%s: %q", errors.ErrMsgUnknownRule, name),
+			}
+		}
+	}
+	if mode := c.Hook.Mode; mode != "" && mode != HookModeFix && mode != HookModeCheck {
+		return &errors.ConfigError{
+			Path: c.Path,
+			Pos:  c.position(MappingValue(MappingValue(documentMapping(root), "hook"), "mode")),
+			Key:  "hook.mode",
+			Err:  fmt.Errorf("%s: %q", errors.ErrMsgUnknownHookMode, mode),
+		}
+	}
+	for i, alias := range c.Aliases {
+		node := valueNode(root, "aliases", i)
+		if _, err := path.Match(alias.Path, ""); err != nil || alias.Path == "" {
+			return &errors.ConfigError{
+				Path: c.Path,
+				Pos:  c.position(MappingValue(node, "path")),
+				Key:  "aliases.path",
+				Err:  fmt.Errorf("%s: %q", errors.ErrMsgInvalidPathPattern, alias.Path),
+			}
+		}
+		if !token.IsIdentifier(alias.Alias) || alias.Alias == "_" {
+			return &errors.ConfigError{
+				Path: c.Path,
+				Pos:  c.position(MappingValue(node, "alias")),
+				Key:  "aliases.alias",
+				Err:  fmt.Errorf("%s: %q", errors.ErrMsgInvalidAlias, alias.Alias),
+			}
+		}
+	}
+	for i, rule := range c.Rules {
+		node := valueNode(root, "rules", i)
+		for j, pattern := range rule.Files {
+			if _, err := path.Match(strings.TrimPrefix(pattern, "!"), ""); err != nil || pattern == "" {
+				return c.invalidRulePattern(sequenceItem(MappingValue(node, "files"), j), "rules.files", pattern)
+			}
+		}
+		for j, deny := range rule.Deny {
+			if !isImportPattern(deny.Path) {
+				return c.invalidRulePattern(MappingValue(sequenceItem(MappingValue(node, "deny"), j), "path"), "rules.deny.path", deny.Path)
+			}
+		}
+		for j, pattern := range rule.Allow {
+			if !isImportPattern(pattern) {
+				return c.invalidRulePattern(sequenceItem(MappingValue(node, "allow"), j), "rules.allow", pattern)
+			}
+		}
+	}
+	groups := make([]string, 0, len(c.Sort))
+	for group := range c.Sort {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		node := MappingValue(documentMapping(root), "sort")
+		switch {
+		case group == SortGroupStd, group == SortGroupThirdParty, group == SortGroupProject,
+			group == SortGroupOrg, group == SortGroupDefault, strings.HasPrefix(group, SortGroupOrg+":"):
+		default:
+			return &errors.ConfigError{
+				Path: c.Path,
+				Pos:  c.position(mappingKey(node, group)),
+				Key:  "sort",
+				Err:  fmt.Errorf("%s: %q", errors.ErrMsgUnknownSortGroup, group),
+			}
+		}
+		for j, key := range c.Sort[group] {
+			if !slices.Contains(SortKeys, key) {
+				return &errors.ConfigError{
+					Path: c.Path,
+					Pos:  c.position(sequenceItem(MappingValue(node, group), j)),
+					Key:  "sort." + group,
+					Err:  fmt.Errorf("%s: %q", errors.ErrMsgUnknownSortKey, key),
+				}
+			}
+		}
+	}
+	policies := map[string]ImportPolicy{"dot-imports": c.DotImports, "blank-imports": c.BlankImports}
+	for _, key := range []string{"dot-imports", "blank-imports"} {
+		policy := policies[key]
+		node := MappingValue(documentMapping(root), key)
+		switch policy.Policy {
+		case "", PolicyAllow, PolicyForbid, PolicyAllowInTests, PolicyAllowInMain:
+		default:
+			return &errors.ConfigError{
+				Path: c.Path,
+				Pos:  c.position(MappingValue(node, "policy")),
+				Key:  key + ".policy",
+				Err:  fmt.Errorf("%s: %q", errors.ErrMsgUnknownImportPolicy, policy.Policy),
+			}
+		}
+		for j, pattern := range policy.Allow {
+			if !isImportPattern(pattern) {
+				return c.invalidRulePattern(sequenceItem(MappingValue(node, "allow"), j), key+".allow", pattern)
+			}
+		}
+		for j, pattern := range policy.Files {
+			if _, err := path.Match(strings.TrimPrefix(pattern, "!"), ""); err != nil || pattern == "" {
+				return c.invalidRulePattern(sequenceItem(MappingValue(node, "files"), j), key+".files", pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// invalidRulePattern reports an invalid pattern of a rule
+func (c *Config) invalidRulePattern(node *yaml.Node, key, pattern string) error {
+	return &errors.ConfigError{
+		Path: c.Path,
+		Pos:  c.position(node),
+		Key:  key,
+		Err:  fmt.Errorf("%s: %q", errors.ErrMsgInvalidPathPattern, pattern),
+	}
+}
+
+// isImportPattern checks if an import path pattern is well-formed
+func isImportPattern(pattern string) bool {
+	if pattern == StdPattern {
+		return true
+	}
+	_, err := path.Match(strings.TrimSuffix(pattern, "/..."), "")
+	return err == nil && pattern != ""
+}
+
+// MatchImport checks if an import path matches a pattern: a path.Match pattern, optionally
+// ending with /... to match subpackages too, or StdPattern given whether the path is standard
+func MatchImport(pattern, importPath string, isStd bool) bool {
+	if pattern == StdPattern {
+		return isStd
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/..."); ok {
+		for p := importPath; p != "." && p != "/"; p = path.Dir(p) {
+			if ok, _ := path.Match(prefix, p); ok {
+				return true
+			}
+		}
+		return false
+	}
+	ok, _ := path.Match(pattern, importPath)
+	return ok
+}
+
+// MatchFile checks if a rule applies to a file: the file matches one of its patterns,
+// or it has none, and none of its exclusions
+func (r Rule) MatchFile(filePath string) bool {
+	rel, err := filepath.Rel(r.Root, filePath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filePath
+	}
+	rel = filepath.ToSlash(rel)
+
+	matched, hasInclusions := false, false
+	for _, pattern := range r.Files {
+		if exclusion, ok := strings.CutPrefix(pattern, "!"); ok {
+			if utils.MatchGlob(exclusion, rel) {
+				return false
+			}
+			continue
+		}
+		hasInclusions = true
+		matched = matched || utils.MatchGlob(pattern, rel)
+	}
+	return matched || !hasInclusions
+}
+
+// position returns the position of a node in the configuration file
+func (c *Config) position(node *yaml.Node) token.Position {
+	if node == nil {
+		return token.Position{Filename: c.Path}
+	}
+	return token.Position{Filename: c.Path, Line: node.Line, Column: node.Column}
+}
+
+// sequenceItem returns the index-th item of a sequence node, the node itself when not found
+func sequenceItem(sequence *yaml.Node, index int) *yaml.Node {
+	if sequence != nil && sequence.Kind == yaml.SequenceNode && index < len(sequence.Content) {
+		return sequence.Content[index]
+	}
+	return sequence
+}
+
+// valueNode returns the node of the index-th item of a top-level sequence, nil when not found
+func valueNode(root *yaml.Node, key string, index int) *yaml.Node {
+	return sequenceItem(MappingValue(documentMapping(root), key), index)
+}
+
+// documentMapping returns the top-level mapping of a document, nil when there is none
+func documentMapping(root *yaml.Node) *yaml.Node {
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return nil
+	}
+	return root.Content[0]
+}
+
+// MappingValue returns the value of a key in a YAML mapping node, nil when not found
+func MappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if i := mappingIndex(mapping, key); i >= 0 {
+		return mapping.Content[i+1]
+	}
+	return nil
+}
+
+// mappingKey returns the node of a key in a mapping node, nil when not found
+func mappingKey(mapping *yaml.Node, key string) *yaml.Node {
+	if i := mappingIndex(mapping, key); i >= 0 {
+		return mapping.Content[i]
+	}
+	return nil
+}
+
+// mappingIndex returns the index of a key in the content of a mapping node, -1 when not found
+func mappingIndex(mapping *yaml.Node, key string) int {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+var lineRegexp = regexp.MustCompile(`line (\d+): (.*)`)
+
+// newDecodeError converts a YAML error into a ConfigError, extracting its line when reported
+func newDecodeError(path string, err error) error {
+	configErr := &errors.ConfigError{Path: path, Pos: token.Position{Filename: path}, Err: err}
+
+	// Type errors aggregate one message per invalid field, the first one is reported
+	message := err.Error()
+	if typeErr, ok := err.(*yaml.TypeError); ok && len(typeErr.Errors) > 0 {
+		message = typeErr.Errors[0]
+	}
+	if match := lineRegexp.FindStringSubmatch(message); match != nil {
+		configErr.Pos.Line, _ = strconv.Atoi(match[1])
+		configErr.Err = fmt.Errorf("%s", match[2])
+	}
+	return configErr
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+)
+
+func TestFind(t *testing.T) {
+	req := require.New(t)
+	root := t.TempDir()
+	nested := filepath.Join(root, "pkg", "api")
+	req.NoError(os.MkdirAll(nested, 0755))
+	goFile := filepath.Join(nested, "api.go")
+	req.NoError(os.WriteFile(goFile, []byte("package api\n"), 0644))
+
+	found, err := Find(goFile)
+	req.NoError(err)
+	req.Empty(found, "no configuration file")
+
+	configFile := filepath.Join(root, FileName)
+	req.NoError(os.WriteFile(configFile, []byte("orgs: [github.com/acme]\n"), 0644))
+	for _, path := range []string{goFile, nested, root} {
+		found, err = Find(path)
+		req.NoError(err)
+		req.Equal(configFile, found, "Find(%q)", path)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		expected   *Config
+		wantKey    string
+		wantLine   int
+		wantColumn int
+	}{
+		{
+			name:    "valid",
+			content: "orgs:\n  - github.com/acme\ncurrent-project: github.com/acme/project\ndisable: [GIG002, unsorted]\nhook:\n  mode: check\n",
+			expected: &Config{
+				Orgs:           []string{"github.com/acme"},
+				CurrentProject: "github.com/acme/project",
+				Disable:        []string{"GIG002", "unsorted"},
+				Hook:           Hook{Mode: HookModeCheck},
+			},
+		},
+		{
+			name:    "aliases",
+			content: "aliases:\n  - path: k8s.io/apimachinery/pkg/apis/meta/v1\n    alias: metav1\n  - path: k8s.io/api/*/v1\n    alias: apiv1\n",
+			expected: &Config{
+				Aliases: []Alias{
+					{Path: "k8s.io/apimachinery/pkg/apis/meta/v1", Alias: "metav1"},
+					{Path: "k8s.io/api/*/v1", Alias: "apiv1"},
+				},
+			},
+		},
+		{
+			name:    "rules",
+			content: "rules:\n  - files: [\"!*_test.go\"]\n    deny:\n      - path: github.com/pkg/errors\n        message: use errors\n        replacement: errors\n    allow: [$std, github.com/acme/...]\n",
+			expected: &Config{
+				Rules: []Rule{{
+					Files: []string{"!*_test.go"},
+					Deny:  []Deny{{Path: "github.com/pkg/errors", Message: "use errors", Replacement: "errors"}},
+					Allow: []string{StdPattern, "github.com/acme/..."},
+				}},
+			},
+		},
+		{
+			name:    "import policies",
+			content: "dot-imports:\n  policy: allow-in-tests\n  allow: [github.com/onsi/gomega]\nblank-imports:\n  policy: allow-in-main\n  files: [\"**/init.go\"]\n",
+			expected: &Config{
+				DotImports:   ImportPolicy{Policy: PolicyAllowInTests, Allow: []string{"github.com/onsi/gomega"}},
+				BlankImports: ImportPolicy{Policy: PolicyAllowInMain, Files: []string{"**/init.go"}},
+			},
+		},
+		{
+			name:    "sort",
+			content: "sort:\n  third-party: [natural, aliased-last]\n  org:github.com/acme: [alias]\n",
+			expected: &Config{
+				Sort: map[string][]string{
+					"third-party":         {SortNatural, SortAliasedLast},
+					"org:github.com/acme": {SortAlias},
+				},
+			},
+		},
+		{
+			name:     "empty",
+			content:  "",
+			expected: &Config{},
+		},
+		{
+			name:     "unknown key",
+			content:  "orgs: []\norder: natural\n",
+			wantLine: 2,
+		},
+		{
+			name:     "syntax error",
+			content:  "orgs: [github.com/acme\n",
+			wantLine: 1,
+		},
+		{
+			name:       "unknown rule",
+			content:    "disable:\n  - GIG002\n  - GIG999\n",
+			wantKey:    "disable",
+			wantLine:   3,
+			wantColumn: 5,
+		},
+		{
+			name:       "unknown hook mode",
+			content:    "hook:\n  mode: amend\n",
+			wantKey:    "hook.mode",
+			wantLine:   2,
+			wantColumn: 9,
+		},
+		{
+			name:       "invalid alias path",
+			content:    "aliases:\n  - path: github.com/pkg/errors\n    alias: pkgerrors\n  - path: k8s.io/api/[/v1\n    alias: apiv1\n",
+			wantKey:    "aliases.path",
+			wantLine:   4,
+			wantColumn: 11,
+		},
+		{
+			name:       "invalid rule pattern",
+			content:    "rules:\n  - deny:\n      - path: log\n      - path: \"[\"\n",
+			wantKey:    "rules.deny.path",
+			wantLine:   4,
+			wantColumn: 15,
+		},
+		{
+			name:       "unknown import policy",
+			content:    "blank-imports:\n  policy: never\n",
+			wantKey:    "blank-imports.policy",
+			wantLine:   2,
+			wantColumn: 11,
+		},
+		{
+			name:       "unknown sort group",
+			content:    "sort:\n  stdlib: [natural]\n",
+			wantKey:    "sort",
+			wantLine:   2,
+			wantColumn: 3,
+		},
+		{
+			name:       "unknown sort key",
+			content:    "sort:\n  std: [path, length]\n",
+			wantKey:    "sort.std",
+			wantLine:   2,
+			wantColumn: 15,
+		},
+		{
+			name:       "invalid alias",
+			content:    "aliases:\n  - path: github.com/pkg/errors\n    alias: pkg-errors\n",
+			wantKey:    "aliases.alias",
+			wantLine:   3,
+			wantColumn: 12,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			path := filepath.Join(t.TempDir(), FileName)
+			req.NoError(os.WriteFile(path, []byte(tt.content), 0644))
+
+			config, err := Load(path)
+			if tt.expected != nil {
+				req.NoError(err)
+				tt.expected.Path = path
+				for i := range tt.expected.Rules {
+					tt.expected.Rules[i].Root = filepath.Dir(path)
+				}
+				tt.expected.DotImports.Root = filepath.Dir(path)
+				tt.expected.BlankImports.Root = filepath.Dir(path)
+				req.Equal(tt.expected, config)
+				return
+			}
+
+			var configErr *errors.ConfigError
+			req.True(stderrors.As(err, &configErr), "expected a ConfigError, got %v", err)
+			req.Equal(path, configErr.Path)
+			req.Equal(tt.wantKey, configErr.Key)
+			req.Equal(tt.wantLine, configErr.Pos.Line)
+			req.Equal(tt.wantColumn, configErr.Pos.Column)
+		})
+	}
+}
+
+func TestMatchImport(t *testing.T) {
+	req := require.New(t)
+	req.True(MatchImport("log", "log", true))
+	req.False(MatchImport("log", "log/slog", true))
+	req.True(MatchImport("github.com/stretchr/testify/...", "github.com/stretchr/testify", false))
+	req.True(MatchImport("github.com/stretchr/testify/...", "github.com/stretchr/testify/require", false))
+	req.False(MatchImport("github.com/stretchr/testify/...", "github.com/stretchr/testifyx", false))
+	req.True(MatchImport("k8s.io/api/*/v1", "k8s.io/api/core/v1", false))
+	req.True(MatchImport(StdPattern, "net/http", true))
+	req.False(MatchImport(StdPattern, "github.com/acme/x", false))
+}
+
+func TestRule_MatchFile(t *testing.T) {
+	req := require.New(t)
+	root := filepath.Join(t.TempDir(), "repo")
+	rule := Rule{Root: root}
+	req.True(rule.MatchFile(filepath.Join(root, "main.go")), "rules without patterns apply to all files")
+
+	rule.Files = []string{"!*_test.go"}
+	req.True(rule.MatchFile(filepath.Join(root, "pkg", "a.go")))
+	req.False(rule.MatchFile(filepath.Join(root, "pkg", "a_test.go")))
+
+	rule.Files = []string{"cmd/**", "internal/**", "!**/testdata/**"}
+	req.True(rule.MatchFile(filepath.Join(root, "cmd", "gig", "main.go")))
+	req.False(rule.MatchFile(filepath.Join(root, "pkg", "cmd", "root.go")))
+	req.False(rule.MatchFile(filepath.Join(root, "internal", "testdata", "a.go")))
+}
+
+func TestImportPolicy_Allows(t *testing.T) {
+	req := require.New(t)
+	root := t.TempDir()
+	file := filepath.Join(root, "pkg", "api", "api.go")
+	test := filepath.Join(root, "pkg", "api", "api_test.go")
+
+	req.True(ImportPolicy{}.Allows("fmt", true, file, "api"))
+	req.False(ImportPolicy{Policy: PolicyForbid}.Allows("fmt", true, file, "api"))
+
+	policy := ImportPolicy{Root: root, Policy: PolicyAllowInTests, Allow: []string{"github.com/onsi/..."}}
+	req.True(policy.Allows("github.com/acme/x", false, test, "api"))
+	req.False(policy.Allows("github.com/acme/x", false, file, "api"))
+	req.True(policy.Allows("github.com/onsi/gomega", false, file, "api"))
+
+	policy = ImportPolicy{Root: root, Policy: PolicyAllowInMain, Files: []string{"**/init.go"}}
+	req.True(policy.Allows("embed", true, file, "main"))
+	req.False(policy.Allows("embed", true, file, "api"))
+	req.True(policy.Allows("embed", true, filepath.Join(root, "pkg", "api", "init.go"), "api"))
+}
@@ -0,0 +1,52 @@
+package daemon
+
+import (
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"time"
+)
+
+// dialTimeout bounds the connection to the daemon, callers fall back to running locally
+const dialTimeout = time.Second
+
+// Client calls a daemon over its Unix socket
+type Client struct {
+	rpc *rpc.Client
+}
+
+// Dial connects to the daemon listening on a socket
+func Dial(path string) (*Client, error) {
+	// Another user may have created the socket to impersonate the daemon
+	if err := checkOwner(path); err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{rpc: jsonrpc.NewClient(conn)}, nil
+}
+
+// Format groups the imports of a file or directory
+func (c *Client) Format(args Args) (*Reply, error) {
+	reply := &Reply{}
+	return reply, c.rpc.Call(ServiceName+".Format", args, reply)
+}
+
+// Check reports the misgrouped imports of a file or directory
+func (c *Client) Check(args Args) (*Reply, error) {
+	reply := &Reply{}
+	return reply, c.rpc.Call(ServiceName+".Check", args, reply)
+}
+
+// Explain describes the settings applied to a file and the violations of its imports
+func (c *Client) Explain(args ExplainArgs) (*ExplainReply, error) {
+	reply := &ExplainReply{}
+	return reply, c.rpc.Call(ServiceName+".Explain", args, reply)
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.rpc.Close()
+}
@@ -0,0 +1,194 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/journal"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+const misgroupedSource = `package main
+
+import (
+	"github.com/acme/platform/auth"
+	"os"
+	"fmt"
+)
+
+func main() { fmt.Println(os.Args, auth.Name) }
+`
+
+// startDaemon serves a daemon on a short socket path, t.TempDir() may exceed the socket path limit
+func startDaemon(t *testing.T) *Client {
+	t.Helper()
+	req := require.New(t)
+	socketDir, err := os.MkdirTemp("", "gig")
+	req.NoError(err)
+	t.Cleanup(func() { os.RemoveAll(socketDir) })
+	socketPath := filepath.Join(socketDir, "gig.sock")
+
+	server, err := Listen(socketPath)
+	req.NoError(err)
+	info, err := os.Stat(socketPath)
+	req.NoError(err)
+	req.Equal(os.FileMode(0600), info.Mode().Perm(), "socket is only accessible by the user")
+	done := make(chan error, 1)
+	go func() { done <- server.Serve() }()
+	t.Cleanup(func() {
+		req.NoError(server.Close())
+		req.NoError(<-done)
+		req.NoFileExists(socketPath, "socket is removed on close")
+	})
+
+	// A second daemon cannot listen on the same socket
+	_, err = Listen(socketPath)
+	req.Error(err)
+
+	client, err := Dial(socketPath)
+	req.NoError(err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func writeProject(t *testing.T) (string, string) {
+	t.Helper()
+	req := require.New(t)
+	root := t.TempDir()
+	req.NoError(os.WriteFile(filepath.Join(root, "go.mod"), []byte("module github.com/test/project\n"), 0644))
+	path := filepath.Join(root, "main.go")
+	req.NoError(os.WriteFile(path, []byte(misgroupedSource), 0644))
+	return root, path
+}
+
+func TestDaemon_FormatAndCheck(t *testing.T) {
+	req := require.New(t)
+	client := startDaemon(t)
+	root, path := writeProject(t)
+	req.NoError(os.WriteFile(filepath.Join(root, ".gig.yaml"), []byte("orgs: [github.com/acme]\n"), 0644))
+
+	reply, err := client.Check(Args{Path: path})
+	req.NoError(err)
+	req.Equal("1 files have misgrouped imports", reply.Error)
+	req.Contains(reply.Output, path+":6:2: GIG004 imports are not sorted within the std group (unsorted)")
+
+	reply, err = client.Check(Args{Path: path, Disable: []string{"GIG001", "GIG002", "GIG003", "GIG004"}})
+	req.NoError(err)
+	req.Empty(reply.Error)
+
+	journalDir := t.TempDir()
+	reply, err = client.Format(Args{Path: path, InPlace: true, JournalDir: journalDir})
+	req.NoError(err)
+	req.Empty(reply.Error)
+	content, err := os.ReadFile(path)
+	req.NoError(err)
+	req.Contains(string(content), "import (\n\t\"fmt\"\n\t\"os\"\n\n\t\"github.com/acme/platform/auth\"\n)")
+	runs, err := journal.List(journalDir)
+	req.NoError(err)
+	req.Len(runs, 1, "in-place runs are journaled")
+
+	reply, err = client.Check(Args{Path: path, Format: string(report.FormatJSON)})
+	req.NoError(err)
+	req.Empty(reply.Error)
+	req.Contains(reply.Output, `"status": "unchanged"`)
+}
+
+func TestDaemon_Explain(t *testing.T) {
+	req := require.New(t)
+	client := startDaemon(t)
+	root, path := writeProject(t)
+	configPath := filepath.Join(root, ".gig.yaml")
+	req.NoError(os.WriteFile(configPath, []byte("orgs: [github.com/acme]\n"), 0644))
+
+	reply, err := client.Explain(ExplainArgs{Path: path})
+	req.NoError(err)
+	req.Equal(configPath, reply.ConfigPath)
+	req.Equal("github.com/test/project", reply.CurrentProject)
+	req.Equal([]string{"github.com/acme"}, reply.Orgs)
+	req.NotEmpty(reply.Violations)
+	req.Equal("GIG003", reply.Violations[0].Code)
+	req.Equal("Import groups are not in the expected order", reply.Violations[0].Description)
+}
+
+func TestService_CacheInvalidation(t *testing.T) {
+	req := require.New(t)
+	root, path := writeProject(t)
+	configPath := filepath.Join(root, ".gig.yaml")
+	req.NoError(os.WriteFile(configPath, []byte("orgs: [github.com/acme]\n"), 0644))
+
+	s := NewService()
+	cfg, _, err := s.config(path)
+	req.NoError(err)
+	req.Equal([]string{"github.com/acme"}, cfg.Orgs)
+	req.Equal("github.com/test/project", s.module(path))
+
+	// Unchanged files are served from the cache
+	cached, _, err := s.config(path)
+	req.NoError(err)
+	req.Same(cfg, cached)
+
+	// Changed files are loaded again
+	later := time.Now().Add(time.Minute)
+	req.NoError(os.WriteFile(configPath, []byte("orgs: [github.com/other]\n"), 0644))
+	req.NoError(os.Chtimes(configPath, later, later))
+	cfg, _, err = s.config(path)
+	req.NoError(err)
+	req.Equal([]string{"github.com/other"}, cfg.Orgs)
+
+	goModPath := filepath.Join(root, "go.mod")
+	req.NoError(os.WriteFile(goModPath, []byte("module github.com/test/renamed\n"), 0644))
+	req.NoError(os.Chtimes(goModPath, later, later))
+	req.Equal("github.com/test/renamed", s.module(path))
+}
+
+func TestListen_DefaultSocket(t *testing.T) {
+	req := require.New(t)
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	req.Equal("/run/user/1000/gig.sock", DefaultSocketPath())
+
+	tempDir, err := os.MkdirTemp("", "gig")
+	req.NoError(err)
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("TMPDIR", tempDir)
+	socketPath := DefaultSocketPath()
+	req.Equal(tempDir, filepath.Dir(filepath.Dir(socketPath)))
+
+	server, err := Listen(socketPath)
+	req.NoError(err)
+	info, err := os.Stat(filepath.Dir(socketPath))
+	req.NoError(err)
+	req.Equal(os.FileMode(0700), info.Mode().Perm(), "the directory of the socket is created private")
+	req.NoError(server.Close())
+
+	// A directory other users can access is refused
+	req.NoError(os.Chmod(filepath.Dir(socketPath), 0755))
+	_, err = Listen(socketPath)
+	req.ErrorContains(err, errors.ErrMsgDirNotPrivate)
+}
+
+func TestDial_SocketOfAnotherUser(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("changing the owner of the socket requires root")
+	}
+	req := require.New(t)
+	socketDir, err := os.MkdirTemp("", "gig")
+	req.NoError(err)
+	t.Cleanup(func() { os.RemoveAll(socketDir) })
+	socketPath := filepath.Join(socketDir, "gig.sock")
+
+	server, err := Listen(socketPath)
+	req.NoError(err)
+	t.Cleanup(func() { server.Close() })
+	req.NoError(os.Chown(socketPath, 65534, 65534))
+
+	_, err = Dial(socketPath)
+	req.ErrorContains(err, errors.ErrMsgOwnedByOtherUser)
+	_, err = Listen(socketPath)
+	req.ErrorContains(err, errors.ErrMsgOwnedByOtherUser, "the socket of another user is not replaced")
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+)
+
+// checkOwner verifies that a file is owned by the current user
+func checkOwner(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("%s: %s", errors.ErrMsgOwnedByOtherUser, path)
+	}
+	return nil
+}
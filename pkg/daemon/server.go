@@ -0,0 +1,111 @@
+package daemon
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+)
+
+// DefaultSocketPath returns the socket path used when none is given, in a directory only
+// accessible by the user: $XDG_RUNTIME_DIR, or a gig-UID directory of the temporary directory
+func DefaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "gig.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("gig-%d", os.Getuid()), "gig.sock")
+}
+
+// Server accepts JSON-RPC connections on a Unix socket
+type Server struct {
+	listener net.Listener
+	rpc      *rpc.Server
+	path     string
+}
+
+// Listen creates the socket of a server, only accessible by the user. A socket left
+// behind by a daemon that is no longer running is replaced, a running daemon or a socket
+// of another user are reported as errors. The directory of the default socket is created
+// if needed, and must not be accessible by other users.
+func Listen(path string) (*Server, error) {
+	if path == DefaultSocketPath() {
+		if err := privateDir(filepath.Dir(path)); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := os.Lstat(path); err == nil {
+		if err := checkOwner(path); err != nil {
+			return nil, err
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s: %s", errors.ErrMsgDaemonAlreadyRunning, path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName(ServiceName, NewService()); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return &Server{listener: listener, rpc: server, path: path}, nil
+}
+
+// privateDir creates a directory only accessible by the user, or checks that an existing
+// one is owned by the user and not accessible by others
+func privateDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if err := checkOwner(dir); err != nil {
+		return err
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return fmt.Errorf("%s: %s", errors.ErrMsgDirNotPrivate, dir)
+	}
+	return nil
+}
+
+// Serve handles connections until the server is closed
+func (s *Server) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if stderrors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.rpc.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+// Close stops accepting connections and removes the socket
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	if removeErr := os.Remove(s.path); removeErr != nil && !os.IsNotExist(removeErr) && err == nil {
+		err = removeErr
+	}
+	return err
+}
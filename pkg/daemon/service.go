@@ -0,0 +1,242 @@
+// Package daemon serves gig over a JSON-RPC Unix socket, so that repeated calls
+// share the lookups of configuration files and go.mod instead of starting a process each
+package daemon
+
+import (
+	"bytes"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/config"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/formatter"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/journal"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/resolver"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/utils"
+)
+
+// ServiceName is the name under which the service methods are exposed, e.g. Gig.Format
+const ServiceName = "Gig"
+
+// Args describes a run of gig on a file or directory. Empty settings fall back to
+// the configuration file found from the path upwards.
+type Args struct {
+	Path              string   // absolute path of the file or directory
+	Orgs              []string // organization prefixes, overriding the configuration file
+	CurrentProject    string   // current project, overriding the configuration file and go.mod
+	Disable           []string // violation rules not reported, added to the configuration file
+	InPlace           bool     // modify the files in place
+	RemoveUnused      bool     // remove the imports that are not referenced by the file
+	AddMissing        bool     // add the imports of unresolved qualifiers
+	RemoveAliases     bool     // remove the aliases equal to the name of the package
+	ConsistentAliases string   // rename the imports named differently than in most files of the package or module
+	ReplaceDenied     bool     // rewrite the denied imports that have a replacement
+	MigrateDeprecated bool     // replace the deprecated symbols of the standard library
+	BackupSuffix      string   // keep the original of the modified files with this suffix, none when empty
+	JournalDir        string   // directory of the journal recording the modified files, none when empty
+	Format            string   // output format, text when empty
+}
+
+// Reply holds what the run would have printed and its error, if any
+type Reply struct {
+	Output string
+	Error  string
+}
+
+// ExplainArgs selects the file to explain
+type ExplainArgs struct {
+	Path string // absolute path of the Go file
+}
+
+// ExplainReply describes the settings resolved for a file and why its imports are not compliant
+type ExplainReply struct {
+	ConfigPath     string // configuration file applied to the file, empty when none
+	CurrentProject string
+	Orgs           []string
+	Violations     []ExplainedViolation
+}
+
+// ExplainedViolation is a violation along with the description of its rule
+type ExplainedViolation struct {
+	report.Violation
+	Description string
+}
+
+// cachedConfig is a configuration file loaded at a given modification time
+type cachedConfig struct {
+	modTime time.Time
+	config  *config.Config
+	err     error
+}
+
+// cachedModule is a module path read from a go.mod file at a given modification time
+type cachedModule struct {
+	modTime time.Time
+	module  string
+}
+
+// Service implements the JSON-RPC methods. Cached entries are invalidated when the
+// modification time of their file changes.
+type Service struct {
+	mu       sync.Mutex
+	configs  map[string]cachedConfig // by configuration file path
+	modules  map[string]cachedModule // by go.mod path
+	resolver *resolver.Resolver      // package lookups of --add-missing
+}
+
+// NewService creates a service with empty caches
+func NewService() *Service {
+	return &Service{
+		configs:  make(map[string]cachedConfig),
+		modules:  make(map[string]cachedModule),
+		resolver: resolver.New(),
+	}
+}
+
+// Format groups the imports of a file or directory, as gig would without --check
+func (s *Service) Format(args Args, reply *Reply) error {
+	return s.run(args, false, reply)
+}
+
+// Check reports the misgrouped imports of a file or directory, as gig --check would
+func (s *Service) Check(args Args, reply *Reply) error {
+	return s.run(args, true, reply)
+}
+
+// Explain describes the settings applied to a file and the violations of its imports
+func (s *Service) Explain(args ExplainArgs, reply *ExplainReply) error {
+	cfg, configPath, err := s.config(args.Path)
+	if err != nil {
+		return err
+	}
+	src, err := os.ReadFile(args.Path)
+	if err != nil {
+		return err
+	}
+
+	formatterConfig := s.formatterConfig(Args{Path: args.Path}, cfg)
+	record, err := formatter.New(formatterConfig).CheckSource(args.Path, src)
+	if err != nil {
+		return err
+	}
+
+	*reply = ExplainReply{
+		ConfigPath:     configPath,
+		CurrentProject: record.Module,
+		Orgs:           formatterConfig.Orgs,
+	}
+	for _, violation := range record.Violations {
+		rule, _ := report.LookupRule(violation.Code)
+		reply.Violations = append(reply.Violations, ExplainedViolation{Violation: violation, Description: rule.Description})
+	}
+	return nil
+}
+
+// run processes a path with the formatter, capturing its output
+func (s *Service) run(args Args, check bool, reply *Reply) error {
+	cfg, _, err := s.config(args.Path)
+	if err != nil {
+		*reply = Reply{Error: err.Error()}
+		return nil
+	}
+
+	var output bytes.Buffer
+	formatterConfig := s.formatterConfig(args, cfg)
+	formatterConfig.FilePath = args.Path
+	formatterConfig.InPlace = args.InPlace
+	formatterConfig.Check = check
+	formatterConfig.Format = report.Format(args.Format)
+	formatterConfig.Output = &output
+	formatterConfig.RemoveUnused = args.RemoveUnused
+	formatterConfig.AddMissing = args.AddMissing
+	formatterConfig.RemoveAliases = args.RemoveAliases
+	formatterConfig.ConsistentAliases = formatter.AliasScope(args.ConsistentAliases)
+	formatterConfig.ReplaceDenied = args.ReplaceDenied
+	formatterConfig.MigrateDeprecated = args.MigrateDeprecated
+	formatterConfig.Resolver = s.resolver
+	formatterConfig.BackupSuffix = args.BackupSuffix
+	if args.InPlace && args.JournalDir != "" {
+		formatterConfig.Journal = journal.NewRecorder(args.JournalDir)
+		defer formatterConfig.Journal.Close()
+	}
+
+	err = formatter.New(formatterConfig).ProcessPath(args.Path)
+	*reply = Reply{Output: output.String()}
+	if err != nil {
+		reply.Error = err.Error()
+	}
+	return nil
+}
+
+// formatterConfig merges the arguments of a call with the configuration file
+func (s *Service) formatterConfig(args Args, cfg *config.Config) formatter.FormatterConfig {
+	formatterConfig := formatter.FormatterConfig{
+		Orgs:           cfg.Orgs,
+		CurrentProject: cfg.CurrentProject,
+		Disable:        append(append([]string{}, cfg.Disable...), args.Disable...),
+		Aliases:        cfg.Aliases,
+		Rules:          cfg.Rules,
+		DotImports:     cfg.DotImports,
+		BlankImports:   cfg.BlankImports,
+		Sort:           cfg.Sort,
+		ResolveModule:  s.module,
+	}
+	if len(args.Orgs) > 0 {
+		formatterConfig.Orgs = args.Orgs
+	}
+	if args.CurrentProject != "" {
+		formatterConfig.CurrentProject = args.CurrentProject
+	}
+	return formatterConfig
+}
+
+// config returns the configuration file applied to a path, an empty one when there is none
+func (s *Service) config(path string) (*config.Config, string, error) {
+	configPath, err := config.Find(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if configPath == "" {
+		return &config.Config{}, "", nil
+	}
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return &config.Config{}, "", nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cached, ok := s.configs[configPath]
+	if !ok || !cached.modTime.Equal(info.ModTime()) {
+		cached.modTime = info.ModTime()
+		cached.config, cached.err = config.Load(configPath)
+		s.configs[configPath] = cached
+	}
+	return cached.config, configPath, cached.err
+}
+
+// module resolves the project module of a file from the closest go.mod
+func (s *Service) module(filePath string) string {
+	goModPath := utils.FindGoMod(filePath)
+	if goModPath == "" {
+		return utils.GetProjectModule(filePath)
+	}
+	info, err := os.Stat(goModPath)
+	if err != nil {
+		return utils.GetProjectModule(filePath)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cached, ok := s.modules[goModPath]
+	if !ok || !cached.modTime.Equal(info.ModTime()) {
+		content, err := os.ReadFile(goModPath)
+		if err != nil {
+			return utils.GetProjectModule(filePath)
+		}
+		cached = cachedModule{modTime: info.ModTime(), module: utils.ModulePath(content)}
+		s.modules[goModPath] = cached
+	}
+	return cached.module
+}
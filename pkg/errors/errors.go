@@ -1,17 +1,81 @@
 package errors
 
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+)
+
 // Error message constants for the go-imports-group application
 const (
 	// File processing errors
 	ErrMsgFailedToReadFile       = "failed to read file"
 	ErrMsgFailedToParseFile      = "failed to parse file"
 	ErrMsgFailedToFormatFile     = "failed to format file"
+	ErrMsgFailedToWriteFile      = "failed to write file"
 	ErrMsgFailedToExtractImports = "failed to extract imports"
+	ErrMsgInvalidLineRange       = "invalid line range, expected start:end"
 
 	// Directory processing errors
 	ErrMsgFailedToCheckPath    = "failed to check path"
 	ErrMsgFailedToFindGoFiles  = "failed to find Go files in directory"
 	ErrMsgFilesFailedToProcess = "%d files failed to process"
+	ErrMsgFirstError           = "first error"
+
+	// Configuration errors
+	ErrMsgInvalidConfig       = "invalid configuration"
+	ErrMsgUnknownFormat       = "unknown output format"
+	ErrMsgFormatNeedsCheck    = "output format requires --check"
+	ErrMsgCheckAndInPlace     = "--check and --in-place cannot be used together"
+	ErrMsgUnknownRule         = "unknown violation rule"
+	ErrMsgUnknownHookMode     = "unknown hook mode, expected fix or check"
+	ErrMsgBackupNeedsInPlace  = "--backup requires --in-place"
+	ErrMsgLinesNeedsFile      = "--lines requires PATH to be a single Go file"
+	ErrMsgInvalidPathPattern  = "invalid import path pattern"
+	ErrMsgInvalidAlias        = "invalid alias, expected a Go identifier"
+	ErrMsgUnknownImportPolicy = "unknown import policy, expected allow, forbid, allow-in-tests or allow-in-main"
+	ErrMsgUnknownAliasScope   = "unknown alias scope, expected package or module"
+	ErrMsgUnknownSortGroup    = "unknown sort group, expected std, third-party, project, org, org:PREFIX or default"
+	ErrMsgUnknownSortKey      = "unknown sort key, expected path, natural, case-insensitive, alias or aliased-last"
+	ErrMsgInvalidRewrite      = "invalid rewrite, expected both paths to end with /... or neither"
+	ErrMsgInvalidMapping      = "invalid mapping, expected two import paths separated by spaces"
+	ErrMsgRewriteMismatch     = "--from and --to must be given the same number of times"
+	ErrMsgNoRewrites          = "no rewrite given, use --from and --to or --mapping"
+
+	// Check errors
+	ErrMsgCheckFailed = "%d files have misgrouped imports"
+
+	// Language server errors
+	ErrMsgExitWithoutShutdown = "exit notification received before shutdown"
+	ErrMsgServerShutDown      = "server is shut down"
+	ErrMsgMethodNotFound      = "method not found"
+	ErrMsgUnsupportedURI      = "unsupported document URI, expected a file URI"
+	ErrMsgInvalidLength       = "invalid Content-Length header"
+	ErrMsgMessageTooLarge     = "message is larger than the maximum size"
+
+	// Daemon errors
+	ErrMsgDaemonAlreadyRunning = "a daemon is already listening on the socket"
+	ErrMsgOwnedByOtherUser     = "file is owned by another user"
+	ErrMsgDirNotPrivate        = "directory is accessible by other users"
+
+	// Hook errors
+	ErrMsgHookExists        = "a pre-commit hook not installed by gig already exists, use --chain to run it before gig"
+	ErrMsgChainedHookExists = "a chained pre-commit hook already exists"
+	ErrMsgHookNotInstalled  = "no pre-commit hook installed by gig"
+	ErrMsgNotAMapping       = "expected a mapping at the top level"
+	ErrMsgPartiallyStaged   = "files with unstaged changes are checked but not fixed, stage or stash their changes first"
+
+	// Undo errors
+	ErrMsgUnknownRun      = "unknown run"
+	ErrMsgNoRunToUndo     = "no in-place run to undo"
+	ErrMsgUndoEditedFiles = "%d files were edited after the run and were not restored"
+
+	// Git errors
+	ErrMsgGitCommandFailed      = "git command failed"
+	ErrMsgInvalidGitRef         = "invalid git ref"
+	ErrMsgFailedToFindGitFiles  = "failed to find changed Go files"
+	ErrMsgChangedSinceAndStaged = "--changed-since and --staged cannot be used together"
 
 	// Standard library generation errors
 	ErrMsgGORootNotFound        = "GOROOT not found"
@@ -19,13 +83,136 @@ const (
 
 	// Info/warning messages
 	WarnMsgProcessingDirWithoutInPlace = "Warning: Processing directory without --in-place flag. No files will be modified."
+	WarnMsgJournalFailed               = "Warning: the run is not journaled and cannot be undone with gig undo: %v"
 	InfoMsgUseInPlaceFlag              = "Use --in-place flag to modify files or specify a single file for stdout output."
 	InfoMsgNoGoFilesFound              = "No Go files found in directory: %s"
 	InfoMsgFoundGoFiles                = "Found %d Go files in directory: %s"
+	InfoMsgNoChangedGoFiles            = "No changed Go files found in: %s"
+	InfoMsgFoundChangedGoFiles         = "Found %d changed Go files in: %s"
 	InfoMsgCurrentProject              = "Current project: %s"
 	InfoMsgProcessedFiles              = "Processed: %s"
+	InfoMsgUntouchedFiles              = "Untouched: %s"
 	InfoMsgErrorProcessing             = "Error processing %s: %v"
 	InfoMsgProcessedCount              = "\nProcessed %d files successfully"
 	InfoMsgErrorCount                  = ", %d files had errors"
+	InfoMsgViolation                   = "%s:%d:%d: %s %s (%s)"
+	InfoMsgViolationCount              = ", %d files have misgrouped imports"
 	InfoMsgCurrentProjectOutput        = "current project: "
+	InfoMsgHookInstalled               = "Installed pre-commit hook: %s"
+	InfoMsgHookChained                 = "Existing pre-commit hook moved to %s, it runs before gig"
+	InfoMsgHookUninstalled             = "Removed pre-commit hook: %s"
+	InfoMsgHookRestored                = "Restored pre-commit hook: %s"
+	InfoMsgFrameworkHookAdded          = "Added gig to %s"
+	InfoMsgFrameworkHookRemoved        = "Removed gig from %s"
+	InfoMsgRestagedFiles               = "Staged %d formatted files"
+	InfoMsgRun                         = "%s\t%s\t%d files"
+	InfoMsgRestoredFile                = "Restored: %s"
+	InfoMsgAlreadyRestoredFile         = "Already restored: %s"
+	InfoMsgEditedFile                  = "Edited after the run, not restored: %s"
+	InfoMsgMissingFile                 = "Removed after the run: %s"
 )
+
+// ReadError reports a file that could not be read
+type ReadError struct {
+	Path string // path of the file
+	Err  error  // underlying I/O error
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("%s %s: %v", ErrMsgFailedToReadFile, e.Path, e.Err)
+}
+
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
+// WriteError reports a file that could not be written
+type WriteError struct {
+	Path string // path of the file
+	Err  error  // underlying I/O error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("%s %s: %v", ErrMsgFailedToWriteFile, e.Path, e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// ParseError reports a Go source file that could not be parsed
+type ParseError struct {
+	Path string         // path of the file
+	Pos  token.Position // position of the first syntax error
+	Err  error          // underlying parser error
+}
+
+// NewParseError creates a ParseError, taking the position from the first error
+// reported by the parser when available
+func NewParseError(path string, err error) *ParseError {
+	parseErr := &ParseError{
+		Path: path,
+		Pos:  token.Position{Filename: path},
+		Err:  err,
+	}
+	var errList scanner.ErrorList
+	if errors.As(err, &errList) && len(errList) > 0 {
+		parseErr.Pos = errList[0].Pos
+	}
+	return parseErr
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s %s: %v", ErrMsgFailedToParseFile, e.Path, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigError reports an invalid configuration value, either from a
+// configuration file or from a command line flag
+type ConfigError struct {
+	Path string         // path of the configuration file, empty for flags
+	Pos  token.Position // position of the value in the configuration file, if known
+	Key  string         // configuration key or flag name
+	Err  error          // underlying error
+}
+
+func (e *ConfigError) Error() string {
+	var location string
+	switch {
+	case e.Pos.IsValid():
+		location = e.Pos.String() + ": "
+	case e.Path != "":
+		location = e.Path + ": "
+	}
+	if e.Key != "" {
+		return fmt.Sprintf("%s: %s%s: %v", ErrMsgInvalidConfig, location, e.Key, e.Err)
+	}
+	return fmt.Sprintf("%s: %s%v", ErrMsgInvalidConfig, location, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError aggregates the errors of the files that failed to process
+type MultiError struct {
+	Errors []error
+}
+
+// Error reports the number of failed files along with the first error, the others
+// are inspected with Unwrap
+func (e *MultiError) Error() string {
+	msg := fmt.Sprintf(ErrMsgFilesFailedToProcess, len(e.Errors))
+	if len(e.Errors) == 0 {
+		return msg
+	}
+	return fmt.Sprintf("%s, %s: %v", msg, ErrMsgFirstError, e.Errors[0])
+}
+
+// Unwrap returns the aggregated errors so that errors.Is and errors.As inspect each of them
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
+}
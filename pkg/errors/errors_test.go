@@ -0,0 +1,87 @@
+package errors
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewParseError(t *testing.T) {
+	req := require.New(t)
+
+	_, err := parser.ParseFile(token.NewFileSet(), "broken.go", "package main\n\nfunc main() {\n\tx := \n}\n", 0)
+	req.Error(err)
+
+	parseErr := NewParseError("broken.go", err)
+	req.Equal("broken.go", parseErr.Path)
+	req.Equal("broken.go", parseErr.Pos.Filename)
+	req.Equal(5, parseErr.Pos.Line)
+	req.Equal(1, parseErr.Pos.Column)
+	req.Contains(parseErr.Error(), ErrMsgFailedToParseFile)
+	req.Equal(err, errors.Unwrap(parseErr))
+
+	// Errors without position information keep the file name only
+	parseErr = NewParseError("other.go", errors.New("boom"))
+	req.Equal("other.go", parseErr.Pos.Filename)
+	req.False(parseErr.Pos.IsValid())
+}
+
+func TestFileErrors_Error(t *testing.T) {
+	req := require.New(t)
+	req.Equal("failed to read file a.go: file does not exist", (&ReadError{Path: "a.go", Err: fs.ErrNotExist}).Error())
+	req.Equal("failed to write file a.go: permission denied", (&WriteError{Path: "a.go", Err: fs.ErrPermission}).Error())
+	req.Equal("failed to parse file b.go: syntax error", (&ParseError{Path: "b.go", Err: errors.New("syntax error")}).Error())
+}
+
+func TestConfigError_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *ConfigError
+		expected string
+	}{
+		{
+			name:     "flag value",
+			err:      &ConfigError{Key: "lines", Err: errors.New("bad range")},
+			expected: "invalid configuration: lines: bad range",
+		},
+		{
+			name:     "configuration file",
+			err:      &ConfigError{Path: ".gig.yaml", Key: "orgs", Err: errors.New("bad value")},
+			expected: "invalid configuration: .gig.yaml: orgs: bad value",
+		},
+		{
+			name:     "configuration file with position",
+			err:      &ConfigError{Path: ".gig.yaml", Pos: token.Position{Filename: ".gig.yaml", Line: 3, Column: 5}, Err: errors.New("bad value")},
+			expected: "invalid configuration: .gig.yaml:3:5: bad value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.err.Error())
+		})
+	}
+}
+
+func TestMultiError(t *testing.T) {
+	req := require.New(t)
+
+	readErr := &ReadError{Path: "a.go", Err: fs.ErrNotExist}
+	parseErr := &ParseError{Path: "b.go", Pos: token.Position{Filename: "b.go", Line: 3, Column: 1}, Err: errors.New("syntax error")}
+	var err error = &MultiError{Errors: []error{readErr, parseErr}}
+
+	req.Equal("2 files failed to process, first error: failed to read file a.go: file does not exist", err.Error())
+	req.ErrorIs(err, fs.ErrNotExist)
+
+	var gotParseErr *ParseError
+	req.True(errors.As(err, &gotParseErr))
+	req.Equal("b.go", gotParseErr.Path)
+	req.Equal(3, gotParseErr.Pos.Line)
+
+	var gotWriteErr *WriteError
+	req.False(errors.As(err, &gotWriteErr))
+}
@@ -0,0 +1,145 @@
+package formatter
+
+import (
+	"fmt"
+	"go/ast"
+	"path"
+	"strings"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+// isRedundantAlias checks if an import is aliased to the name of its package, read from
+// the package clause of its sources when they are on disk, assumed from its path otherwise.
+// Aliases required by the configuration are never redundant.
+func (g *formatter) isRedundantAlias(imp Import) bool {
+	switch imp.Name {
+	case "", "_", ".":
+		return false
+	}
+	return imp.Name != g.requiredAlias(imp.Path) && imp.Name == g.getResolver().PackageName(g.getFilePath(), imp.Path)
+}
+
+// removeRedundantAliases drops the aliases equal to the name of the imported package
+func (g *formatter) removeRedundantAliases(imports []Import) []Import {
+	for i := range imports {
+		if g.isRedundantAlias(imports[i]) {
+			imports[i].Name = ""
+		}
+	}
+	return imports
+}
+
+// requiredAlias returns the alias of the first configured rule matching an import path,
+// empty when none matches
+func (g *formatter) requiredAlias(importPath string) string {
+	for _, alias := range g.config.Aliases {
+		if ok, _ := path.Match(alias.Path, importPath); ok {
+			return alias.Alias
+		}
+	}
+	return ""
+}
+
+// wrongAlias returns the alias required for an import when it is referred to by another
+// name, empty otherwise. Blank and dot imports are left alone.
+func (g *formatter) wrongAlias(imp Import) string {
+	if imp.Name == "_" || imp.Name == "." {
+		return ""
+	}
+	required := g.requiredAlias(imp.Path)
+	if required == "" || required == g.importName(imp) {
+		return ""
+	}
+	return required
+}
+
+// importName returns the name the file refers to an import by
+func (g *formatter) importName(imp Import) string {
+	if imp.Name != "" {
+		return imp.Name
+	}
+	return g.getResolver().PackageName(g.getFilePath(), imp.Path)
+}
+
+// enforceAliases renames the imports that do not use their required alias, or the name
+// most files of the scope use with --consistent-aliases, along with the uses of their
+// previous name in the file. It returns the number of renamed imports. Imports whose
+// alias is already taken by another name are not renamed and reported as warnings.
+func (g *formatter) enforceAliases(file *ast.File, imports []Import) (int, []report.Violation) {
+	renamed := 0
+	var warnings []report.Violation
+	for i := range imports {
+		required, rule := g.wrongAlias(imports[i]), report.RuleRequiredAlias
+		if required == "" {
+			required, rule = g.consistentAlias(file, imports[i]), report.RuleInconsistentAlias
+		}
+		if required == "" {
+			continue
+		}
+		if g.isNameTaken(file, imports, required) {
+			warnings = append(warnings, g.importViolation(file, imports[i], rule,
+				"%q cannot be renamed to %s, the name is already used", imports[i].Path, required))
+			continue
+		}
+
+		renameQualifier(file, g.importName(imports[i]), required)
+		imports[i].Name = required
+		if rule == report.RuleInconsistentAlias && required == g.getResolver().PackageName(g.getFilePath(), imports[i].Path) {
+			imports[i].Name = "" // Most files do not alias it
+		}
+		renamed++
+	}
+	return renamed, warnings
+}
+
+// isNameTaken checks if a name already refers to an import, to a declaration of the file
+// or of its package, or to an unresolved qualifier
+func (g *formatter) isNameTaken(file *ast.File, imports []Import, name string) bool {
+	for _, imp := range imports {
+		if g.importName(imp) == name {
+			return true
+		}
+	}
+	if usedQualifiers(file)[name] || g.packageScope(file).decls[name] {
+		return true
+	}
+
+	taken := false
+	ast.Inspect(file, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && ident.Obj != nil && ident.Name == name {
+			taken = true
+		}
+		return !taken
+	})
+	return taken
+}
+
+// renameQualifier renames the package qualifiers of selector expressions
+func renameQualifier(file *ast.File, from, to string) {
+	ast.Inspect(file, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := selector.X.(*ast.Ident); ok && ident.Obj == nil && ident.Name == from {
+				ident.Name = to
+			}
+		}
+		return true
+	})
+}
+
+// importViolation describes a violation positioned at the import of a path in the file
+func (g *formatter) importViolation(file *ast.File, imp Import, rule, format string, args ...any) report.Violation {
+	violation := report.Violation{
+		Code:    report.RuleCode(rule),
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+	}
+	for _, spec := range file.Imports {
+		if strings.Trim(spec.Path.Value, `"`) == imp.Path {
+			pos := g.fileSet.Position(spec.Pos())
+			violation.Line, violation.Column = pos.Line, pos.Column
+			break
+		}
+	}
+	return violation
+}
@@ -0,0 +1,209 @@
+package formatter
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/config"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+func TestFormatter_RemoveRedundantAliases(t *testing.T) {
+	req := require.New(t)
+	dir := writeModule(t, map[string]string{
+		"go.mod":               "module github.com/test/project\n",
+		"db/connector/v2/c.go": "package connector\n",
+		"logging/log.go":       "package log\n",
+	})
+
+	src := `package main
+
+import (
+	fmt "fmt"
+	_ "embed"
+	. "strings"
+	yaml "gopkg.in/yaml.v3"
+	redis "github.com/go-redis/redis/v9"
+	kit "github.com/acme/go-kit"
+	connector "github.com/test/project/db/connector/v2"
+	connectorv2 "github.com/test/project/db/connector/v2/client"
+	log "github.com/test/project/logging"
+	logging "github.com/test/project/logging/v3"
+)
+`
+	expected := `package main
+
+import (
+	_ "embed"
+	"fmt"
+	. "strings"
+
+	"github.com/acme/go-kit"
+	"github.com/go-redis/redis/v9"
+	"gopkg.in/yaml.v3"
+
+	"github.com/test/project/db/connector/v2"
+	connectorv2 "github.com/test/project/db/connector/v2/client"
+	"github.com/test/project/logging"
+	"github.com/test/project/logging/v3"
+)
+`
+	g := New(FormatterConfig{FilePath: filepath.Join(dir, "main.go"), CurrentProject: "github.com/test/project", RemoveAliases: true})
+	result, err := g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Equal(expected, string(result.output))
+}
+
+func TestFormatter_FindViolations_RedundantAlias(t *testing.T) {
+	req := require.New(t)
+	src := "package main\n\nimport (\n\tfmt \"fmt\"\n\tstr \"strings\"\n)\n"
+
+	g := New(FormatterConfig{FilePath: "main.go", CurrentProject: "github.com/test/project", Check: true, RemoveAliases: true})
+	result, err := g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Equal([]report.Violation{{
+		Code:    "GIG009",
+		Rule:    report.RuleRedundantAlias,
+		Message: `alias fmt of "fmt" is the name of the package`,
+		Line:    4,
+		Column:  2,
+	}}, result.violations)
+	req.Equal("import (\n\t\"fmt\"\n\tstr \"strings\"\n)", result.fix.Replacement)
+}
+
+var testAliases = []config.Alias{
+	{Path: "k8s.io/apimachinery/pkg/apis/meta/v1", Alias: "metav1"},
+	{Path: "k8s.io/api/*/v1", Alias: "corev1"},
+	{Path: "github.com/pkg/errors", Alias: "pkgerrors"},
+}
+
+func TestFormatter_EnforceAliases(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+		warnings []string
+	}{
+		{
+			name: "imports and their uses are renamed",
+			src: `package main
+
+import (
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func main() {
+	pod := v1.Pod{ObjectMeta: meta.ObjectMeta{Name: "a"}}
+	_ = errors.Wrap(nil, pod.Name)
+}
+`,
+			expected: `package main
+
+import (
+	pkgerrors "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func main() {
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a"}}
+	_ = pkgerrors.Wrap(nil, pod.Name)
+}
+`,
+		},
+		{
+			name: "shadowing names are not renamed",
+			src: `package main
+
+import (
+	"github.com/pkg/errors"
+)
+
+func main() {
+	_ = errors.New("a")
+	{
+		errors := struct{ New int }{}
+		_ = errors.New
+	}
+}
+`,
+			expected: `package main
+
+import (
+	pkgerrors "github.com/pkg/errors"
+)
+
+func main() {
+	_ = pkgerrors.New("a")
+	{
+		errors := struct{ New int }{}
+		_ = errors.New
+	}
+}
+`,
+		},
+		{
+			name: "taken names are reported",
+			src: `package main
+
+import (
+	. "k8s.io/api/apps/v1"
+	"github.com/pkg/errors"
+)
+
+var pkgerrors = errors.New("a")
+
+func main() { _ = Deployment{} }
+`,
+			expected: `package main
+
+import (
+	"github.com/pkg/errors"
+	. "k8s.io/api/apps/v1"
+)
+
+var pkgerrors = errors.New("a")
+
+func main() { _ = Deployment{} }
+`,
+			warnings: []string{`"github.com/pkg/errors" cannot be renamed to pkgerrors, the name is already used`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			g := New(FormatterConfig{FilePath: filepath.Join(t.TempDir(), "main.go"), CurrentProject: "github.com/test/project", Aliases: testAliases})
+			result, err := g.formatSource([]byte(tt.src))
+			req.NoError(err)
+			req.Equal(tt.expected, string(result.output))
+
+			var warnings []string
+			for _, warning := range result.warnings {
+				warnings = append(warnings, warning.Message)
+			}
+			req.Equal(tt.warnings, warnings)
+		})
+	}
+}
+
+func TestFormatter_FindViolations_RequiredAlias(t *testing.T) {
+	req := require.New(t)
+	src := "package main\n\nimport (\n\t\"github.com/pkg/errors\"\n\tmetav1 \"k8s.io/apimachinery/pkg/apis/meta/v1\"\n)\n\nvar _ = errors.New\nvar _ = metav1.Now\n"
+
+	g := New(FormatterConfig{FilePath: filepath.Join(t.TempDir(), "main.go"), CurrentProject: "github.com/test/project", Check: true, Aliases: testAliases})
+	result, err := g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Equal([]report.Violation{{
+		Code:    "GIG010",
+		Rule:    report.RuleRequiredAlias,
+		Message: `"github.com/pkg/errors" should be imported as pkgerrors`,
+		Line:    4,
+		Column:  2,
+	}}, result.violations)
+	req.Nil(result.fix, "renaming changes lines outside of the import declaration")
+}
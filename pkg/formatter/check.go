@@ -0,0 +1,414 @@
+package formatter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+// ignoreDirective suppresses the violations of an import when placed in its doc or line
+// comment, either all of them or only the codes listed after it, e.g. //gig:ignore GIG002
+const ignoreDirective = "//gig:ignore"
+
+// groupKey identifies the block an import belongs to: its group and, for
+// organization imports, its project within the organization
+type groupKey struct {
+	group   ImportGroup
+	project string
+}
+
+// importEntry is an import of the original source along with its position
+type importEntry struct {
+	imp    Import
+	line   int
+	column int
+	key    groupKey
+	ignore map[string]bool // codes suppressed by a directive, "*" for all of them
+}
+
+// violationFunc adds a violation of a rule at the position of an import, unless it is
+// disabled or suppressed
+type violationFunc func(rule string, entry importEntry, format string, args ...any)
+
+// importSegment is a run of consecutive imports of the same group key
+type importSegment struct {
+	key     groupKey
+	block   int
+	entries []importEntry
+}
+
+// findViolations compares the imports of the original source with the expected
+// grouping and describes why they are not compliant. Missing imports are reported
+// when they resolve to at least one package, and the uses of deprecated symbols when
+// they are migrated.
+func (g *formatter) findViolations(src []byte, file *ast.File, missing []missingImport) []report.Violation {
+	var violations []report.Violation
+	addViolation := func(rule string, entry importEntry, format string, args ...any) {
+		code := report.RuleCode(rule)
+		if g.isDisabled(code) || entry.ignore["*"] || entry.ignore[code] {
+			return
+		}
+		violations = append(violations, report.Violation{
+			Code:    code,
+			Rule:    rule,
+			Message: fmt.Sprintf(format, args...),
+			Line:    entry.line,
+			Column:  entry.column,
+		})
+	}
+
+	blocks := g.importBlocks(src, file)
+	blocks = g.duplicateViolations(blocks, addViolation)
+	blocks = g.unusedViolations(file, blocks, addViolation)
+	for _, block := range blocks {
+		for _, entry := range block {
+			g.aliasViolations(file, entry, addViolation)
+			g.policyViolations(file, entry, addViolation)
+			g.rewriteViolations(entry, addViolation)
+			g.denyViolations(entry, addViolation)
+		}
+	}
+
+	// The owner of a block is its most frequent group key, the first one on ties
+	owners := make([]groupKey, len(blocks))
+	ownedBlocks := make(map[groupKey]int)
+	for i, block := range blocks {
+		counts := make(map[groupKey]int)
+		for _, entry := range block {
+			counts[entry.key]++
+		}
+		owners[i] = block[0].key
+		for _, entry := range block {
+			if counts[entry.key] > counts[owners[i]] {
+				owners[i] = entry.key
+			}
+		}
+		ownedBlocks[owners[i]]++
+	}
+
+	var segments []importSegment
+	for i, block := range blocks {
+		runs := splitRuns(block, i)
+		runCounts := make(map[groupKey]int)
+		for _, run := range runs {
+			runCounts[run.key]++
+		}
+
+		for j, run := range runs {
+			if run.key != owners[i] {
+				ownerBefore, ownerAfter := false, false
+				for k, other := range runs {
+					if other.key == owners[i] {
+						ownerBefore = ownerBefore || k < j
+						ownerAfter = ownerAfter || k > j
+					}
+				}
+
+				// Imports having a block of their own elsewhere, or scattered in the block, are misplaced.
+				// Otherwise the block merges two groups that only lack a separating blank line.
+				if ownedBlocks[run.key] > 0 || runCounts[run.key] > 1 || (ownerBefore && ownerAfter) {
+					for _, entry := range run.entries {
+						addViolation(report.RuleWrongGroup, entry, "%q belongs to the %s group but is in the %s block",
+							entry.imp.Path, g.groupKeyName(run.key), g.groupKeyName(owners[i]))
+					}
+					continue
+				}
+				if j > 0 {
+					addViolation(report.RuleMissingBlankLine, run.entries[0], "missing blank line between %s and %s imports",
+						g.groupKeyName(runs[j-1].key), g.groupKeyName(run.key))
+				}
+			}
+
+			// Merge with the previous segment when only a misplaced run was in between
+			if last := len(segments) - 1; last >= 0 && segments[last].block == i && segments[last].key == run.key {
+				segments[last].entries = append(segments[last].entries, run.entries...)
+				continue
+			}
+			segments = append(segments, run)
+		}
+	}
+
+	for i, segment := range segments {
+		if i > 0 {
+			prev := segments[i-1]
+			switch {
+			case segment.key == prev.key:
+				addViolation(report.RuleSplitGroup, segment.entries[0], "%s imports are split across several blocks",
+					g.groupKeyName(segment.key))
+			case g.groupKeyLess(segment.key, prev.key):
+				addViolation(report.RuleWrongOrder, segment.entries[0], "%s imports should come before %s imports",
+					g.groupKeyName(segment.key), g.groupKeyName(prev.key))
+			}
+		}
+
+		less := g.importLess(segment.key.group)
+		for j := 1; j < len(segment.entries); j++ {
+			if less(segment.entries[j].imp, segment.entries[j-1].imp) {
+				addViolation(report.RuleUnsorted, segment.entries[j], "imports are not sorted within the %s group",
+					g.groupKeyName(segment.key))
+				break
+			}
+		}
+	}
+
+	for _, m := range missing {
+		if len(m.candidates) > 0 {
+			addViolation(report.RuleMissingImport, importEntry{line: m.pos.Line, column: m.pos.Column}, "%s", missingImportMessage(m))
+		}
+	}
+
+	if g.config.MigrateDeprecated {
+		for _, use := range g.deprecatedUses(file) {
+			pos := g.fileSet.Position(use.selector.Pos())
+			addViolation(report.RuleDeprecatedSymbol, importEntry{line: pos.Line, column: pos.Column}, "%s.%s is deprecated, use %s.%s",
+				use.migration.Path, use.migration.Symbol, use.migration.NewPath, use.migration.NewSymbol)
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Line < violations[j].Line
+	})
+	return violations
+}
+
+// importBlocks splits the imports of the original source into blocks separated by blank
+// lines or by separate import declarations
+func (g *formatter) importBlocks(src []byte, file *ast.File) [][]importEntry {
+	tokFile := g.fileSet.File(file.Pos())
+	projectModule := g.getCurrentProject()
+
+	var blocks [][]importEntry
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+
+		var block []importEntry
+		prevEndLine := 0
+		for _, spec := range genDecl.Specs {
+			importSpec, ok := spec.(*ast.ImportSpec)
+			if !ok {
+				continue
+			}
+
+			pos := g.fileSet.Position(importSpec.Pos())
+			entry := importEntry{
+				imp:    Import{Path: strings.Trim(importSpec.Path.Value, `"`)},
+				line:   pos.Line,
+				column: pos.Column,
+			}
+			if importSpec.Name != nil {
+				entry.imp.Name = importSpec.Name.Name
+			}
+			entry.ignore = ignoredCodes(importSpec.Doc, importSpec.Comment)
+
+			startLine := pos.Line
+			if importSpec.Doc != nil {
+				startLine = g.fileSet.Position(importSpec.Doc.Pos()).Line
+			}
+			if prevEndLine > 0 && hasBlankLine(src, tokFile, prevEndLine+1, startLine-1) && len(block) > 0 {
+				blocks = append(blocks, block)
+				block = nil
+			}
+			prevEndLine = g.fileSet.Position(importSpec.End()).Line
+
+			entry.imp.Group = g.classifyImport(entry.imp.Path, projectModule)
+			entry.key = groupKey{group: entry.imp.Group}
+			if entry.imp.Group >= OrgGroupBase {
+				entry.imp.OrgIndex, entry.imp.ProjectName = g.getOrgInfo(entry.imp.Path)
+				entry.key.project = entry.imp.ProjectName
+			}
+			block = append(block, entry)
+		}
+
+		if len(block) > 0 {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// duplicateViolations reports the imports of a path imported earlier and leaves them out of the blocks
+func (g *formatter) duplicateViolations(blocks [][]importEntry, addViolation violationFunc) [][]importEntry {
+	firstLines := make(map[string]int)
+	return filterImports(blocks, func(entry importEntry) bool {
+		if firstLine, ok := firstLines[entry.imp.Path]; ok {
+			addViolation(report.RuleDuplicateImport, entry, "%q is already imported on line %d", entry.imp.Path, firstLine)
+			return false
+		}
+		firstLines[entry.imp.Path] = entry.line
+		return true
+	})
+}
+
+// unusedViolations reports the unused imports and leaves them out of the blocks, when they are removed
+func (g *formatter) unusedViolations(file *ast.File, blocks [][]importEntry, addViolation violationFunc) [][]importEntry {
+	if !g.config.RemoveUnused {
+		return blocks
+	}
+	unused := g.unusedImports(file)
+	return filterImports(blocks, func(entry importEntry) bool {
+		if unused[entry.imp.Path] {
+			addViolation(report.RuleUnusedImport, entry, "%q is not used", entry.imp.Path)
+			return false
+		}
+		return true
+	})
+}
+
+// aliasViolations reports a redundant alias, when removed, and an alias differing from
+// the required one or from the other files
+func (g *formatter) aliasViolations(file *ast.File, entry importEntry, addViolation violationFunc) {
+	if g.config.RemoveAliases && g.isRedundantAlias(entry.imp) {
+		addViolation(report.RuleRedundantAlias, entry, "alias %s of %q is the name of the package", entry.imp.Name, entry.imp.Path)
+	}
+	if required := g.wrongAlias(entry.imp); required != "" {
+		addViolation(report.RuleRequiredAlias, entry, "%q should be imported as %s", entry.imp.Path, required)
+	} else if consistent := g.consistentAlias(file, entry.imp); consistent != "" {
+		addViolation(report.RuleInconsistentAlias, entry, "%q should be imported as %s like in most files of the %s",
+			entry.imp.Path, consistent, g.config.ConsistentAliases)
+	}
+}
+
+// policyViolations reports a dot or blank import not allowed by its policy
+func (g *formatter) policyViolations(file *ast.File, entry importEntry, addViolation violationFunc) {
+	if rule, message := g.importPolicyViolation(file, entry.imp); rule != "" {
+		addViolation(rule, entry, "%s", message)
+	}
+}
+
+// rewriteViolations reports an import whose path is rewritten
+func (g *formatter) rewriteViolations(entry importEntry, addViolation violationFunc) {
+	if newPath, ok := g.rewrittenPath(entry.imp.Path); ok {
+		addViolation(report.RuleRewrittenImport, entry, "%q is rewritten to %q", entry.imp.Path, newPath)
+	}
+}
+
+// denyViolations reports an import denied by the rules
+func (g *formatter) denyViolations(entry importEntry, addViolation violationFunc) {
+	if denial := g.deniedImport(entry.imp.Path); denial != nil {
+		addViolation(report.RuleDeniedImport, entry, "%s", denial.message)
+	}
+}
+
+// filterImports returns the blocks with the imports kept by keep, dropping the emptied blocks
+func filterImports(blocks [][]importEntry, keep func(importEntry) bool) [][]importEntry {
+	var filtered [][]importEntry
+	for _, block := range blocks {
+		var kept []importEntry
+		for _, entry := range block {
+			if keep(entry) {
+				kept = append(kept, entry)
+			}
+		}
+		if len(kept) > 0 {
+			filtered = append(filtered, kept)
+		}
+	}
+	return filtered
+}
+
+// ignoredCodes collects the codes suppressed by the ignore directives of an import
+func ignoredCodes(groups ...*ast.CommentGroup) map[string]bool {
+	var ignore map[string]bool
+	for _, group := range groups {
+		if group == nil {
+			continue
+		}
+		for _, comment := range group.List {
+			args, ok := strings.CutPrefix(comment.Text, ignoreDirective)
+			if !ok || (args != "" && args[0] != ' ' && args[0] != '\t') {
+				continue
+			}
+			if ignore == nil {
+				ignore = make(map[string]bool)
+			}
+
+			names := strings.FieldsFunc(args, func(r rune) bool {
+				return r == ',' || r == ' ' || r == '\t'
+			})
+			if len(names) == 0 {
+				ignore["*"] = true
+			}
+			for _, name := range names {
+				if rule, ok := report.LookupRule(name); ok {
+					ignore[rule.Code] = true
+				}
+			}
+		}
+	}
+	return ignore
+}
+
+// isDisabled checks if the violations of a code are disabled by the configuration
+func (g *formatter) isDisabled(code string) bool {
+	for _, name := range g.config.Disable {
+		if rule, ok := report.LookupRule(name); ok && rule.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// splitRuns splits a block into runs of consecutive imports with the same group key
+func splitRuns(block []importEntry, blockIndex int) []importSegment {
+	var runs []importSegment
+	for _, entry := range block {
+		if last := len(runs) - 1; last >= 0 && runs[last].key == entry.key {
+			runs[last].entries = append(runs[last].entries, entry)
+			continue
+		}
+		runs = append(runs, importSegment{key: entry.key, block: blockIndex, entries: []importEntry{entry}})
+	}
+	return runs
+}
+
+// hasBlankLine checks if any line between firstLine and lastLine (inclusive) is blank
+func hasBlankLine(src []byte, tokFile *token.File, firstLine, lastLine int) bool {
+	for line := firstLine; line <= lastLine; line++ {
+		start := tokFile.Offset(tokFile.LineStart(line))
+		end := len(src)
+		if line < tokFile.LineCount() {
+			end = tokFile.Offset(tokFile.LineStart(line + 1))
+		}
+		if strings.TrimSpace(string(src[start:end])) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// groupOrder returns the position of a group in the import declaration, matching replaceImports
+func (g *formatter) groupOrder(group ImportGroup) int {
+	switch group {
+	case StdGroup:
+		return 0
+	case ThirdPartyGroup:
+		return 1
+	case ProjectGroup:
+		return 2 + len(g.getOrgs())
+	}
+	return 2 + int(group-OrgGroupBase)
+}
+
+// groupKeyLess checks if imports of group key a are expected before the ones of b
+func (g *formatter) groupKeyLess(a, b groupKey) bool {
+	if a.group != b.group {
+		return g.groupOrder(a.group) < g.groupOrder(b.group)
+	}
+	return a.project < b.project
+}
+
+// groupKeyName returns the name of a group key used in violation messages
+func (g *formatter) groupKeyName(key groupKey) string {
+	name := g.groupName(key.group)
+	if key.group >= OrgGroupBase && key.project != "" {
+		name += "/" + key.project
+	}
+	return name
+}
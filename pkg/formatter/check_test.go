@@ -0,0 +1,205 @@
+package formatter
+
+import (
+	"bytes"
+	"go/parser"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+func TestFormatter_FindViolations(t *testing.T) {
+	tests := []struct {
+		name     string
+		imports  string
+		expected []report.Violation
+	}{
+		{
+			name: "compliant",
+			imports: `import (
+	"fmt"
+	"os"
+
+	"github.com/external/lib"
+
+	"github.com/acme/platform/auth"
+
+	"github.com/test/project/pkg"
+)`,
+		},
+		{
+			name: "unsorted group",
+			imports: `import (
+	"os"
+	"fmt"
+)`,
+			expected: []report.Violation{
+				{Code: "GIG004", Rule: report.RuleUnsorted, Message: "imports are not sorted within the std group", Line: 5, Column: 2},
+			},
+		},
+		{
+			name: "missing blank line",
+			imports: `import (
+	"fmt"
+	"github.com/external/lib"
+)`,
+			expected: []report.Violation{
+				{Code: "GIG002", Rule: report.RuleMissingBlankLine, Message: "missing blank line between std and third-party imports", Line: 5, Column: 2},
+			},
+		},
+		{
+			name: "import in the block of another group",
+			imports: `import (
+	"fmt"
+
+	"github.com/external/lib"
+	"os"
+	"github.com/external/other"
+)`,
+			expected: []report.Violation{
+				{Code: "GIG001", Rule: report.RuleWrongGroup, Message: `"os" belongs to the std group but is in the third-party block`, Line: 7, Column: 2},
+			},
+		},
+		{
+			name: "groups in the wrong order",
+			imports: `import (
+	"github.com/external/lib"
+
+	"fmt"
+)`,
+			expected: []report.Violation{
+				{Code: "GIG003", Rule: report.RuleWrongOrder, Message: "std imports should come before third-party imports", Line: 6, Column: 2},
+			},
+		},
+		{
+			name: "group split across declarations",
+			imports: `import "fmt"
+import "os"`,
+			expected: []report.Violation{
+				{Code: "GIG006", Rule: report.RuleSplitGroup, Message: "std imports are split across several blocks", Line: 4, Column: 8},
+			},
+		},
+		{
+			name: "duplicate import",
+			imports: `import (
+	"fmt"
+	str "strings"
+	"strings"
+)`,
+			expected: []report.Violation{
+				{Code: "GIG005", Rule: report.RuleDuplicateImport, Message: `"strings" is already imported on line 5`, Line: 6, Column: 2},
+			},
+		},
+		{
+			name: "org projects",
+			imports: `import (
+	"github.com/acme/platform/auth"
+	"github.com/acme/billing/invoice"
+)`,
+			expected: []report.Violation{
+				{Code: "GIG002", Rule: report.RuleMissingBlankLine, Message: "missing blank line between org:github.com/acme/platform and org:github.com/acme/billing imports", Line: 5, Column: 2},
+				{Code: "GIG003", Rule: report.RuleWrongOrder, Message: "org:github.com/acme/billing imports should come before org:github.com/acme/platform imports", Line: 5, Column: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			src := []byte("package main\n\n" + tt.imports + "\n")
+
+			g := New(FormatterConfig{
+				FilePath:       "main.go",
+				Orgs:           []string{"github.com/acme"},
+				CurrentProject: "github.com/test/project",
+			})
+			file, err := parser.ParseFile(g.fileSet, "main.go", src, parser.ParseComments)
+			req.NoError(err)
+			req.Equal(tt.expected, g.findViolations(src, file, nil))
+		})
+	}
+}
+
+func TestFormatter_ProcessPath_Check(t *testing.T) {
+	req := require.New(t)
+	dir := t.TempDir()
+
+	misgrouped := "package main\n\nimport (\n\t\"os\"\n\t\"fmt\"\n)\n\nfunc main() { fmt.Println(os.Args) }\n"
+	compliant := "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc other() { fmt.Println(os.Args) }\n"
+	req.NoError(os.WriteFile(filepath.Join(dir, "main.go"), []byte(misgrouped), 0644))
+	req.NoError(os.WriteFile(filepath.Join(dir, "other.go"), []byte(compliant), 0644))
+
+	var buf bytes.Buffer
+	g := New(FormatterConfig{
+		CurrentProject: "github.com/test/project",
+		Check:          true,
+		Output:         &buf,
+	})
+	err := g.ProcessPath(dir)
+	req.EqualError(err, "1 files have misgrouped imports")
+
+	req.Contains(buf.String(), filepath.Join(dir, "main.go")+":5:2: GIG004 imports are not sorted within the std group (unsorted)")
+	req.NotContains(buf.String(), "other.go")
+
+	// Check mode never modifies files
+	content, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	req.NoError(err)
+	req.Equal(misgrouped, string(content))
+
+	// Compliant files pass the check
+	buf.Reset()
+	g = New(FormatterConfig{
+		CurrentProject: "github.com/test/project",
+		Check:          true,
+		Output:         &buf,
+	})
+	req.NoError(g.ProcessPath(filepath.Join(dir, "other.go")))
+}
+
+func TestFormatter_FindViolations_Suppressed(t *testing.T) {
+	src := []byte(`package main
+
+import (
+	"os"
+	"fmt" //gig:ignore GIG004
+	"github.com/external/lib"
+
+	//gig:ignore
+	"strings"
+	"github.com/external/other"
+)
+`)
+
+	tests := []struct {
+		name     string
+		disable  []string
+		expected []string
+	}{
+		{"directives only", nil, []string{"GIG002", "GIG002"}},
+		{"disabled by code", []string{"GIG002"}, nil},
+		{"disabled by rule", []string{"missing-blank-line"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			g := New(FormatterConfig{
+				FilePath:       "main.go",
+				CurrentProject: "github.com/test/project",
+				Disable:        tt.disable,
+			})
+			file, err := parser.ParseFile(g.fileSet, "main.go", src, parser.ParseComments)
+			req.NoError(err)
+
+			var codes []string
+			for _, violation := range g.findViolations(src, file, nil) {
+				codes = append(codes, violation.Code)
+			}
+			req.Equal(tt.expected, codes)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package formatter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/utils"
+)
+
+// AliasScope selects the files whose imports of a path are named consistently
+type AliasScope string
+
+const (
+	AliasScopeNone    AliasScope = ""        // aliases are not compared across files
+	AliasScopePackage AliasScope = "package" // the files of the package of a file
+	AliasScopeModule  AliasScope = "module"  // the files of the module of a file
+)
+
+// ParseAliasScope parses the scope of the --consistent-aliases flag
+func ParseAliasScope(name string) (AliasScope, error) {
+	switch scope := AliasScope(name); scope {
+	case AliasScopeNone, AliasScopePackage, AliasScopeModule:
+		return scope, nil
+	}
+	return "", &errors.ConfigError{
+		Key: "consistent-aliases",
+		Err: fmt.Errorf("%s: %q", errors.ErrMsgUnknownAliasScope, name),
+	}
+}
+
+// aliasCensus counts the files of a scope importing each path under each name
+type aliasCensus map[string]map[string]int
+
+// consistentAlias returns the name most files of the scope import a path by when an
+// import is named otherwise, empty when it is consistent. Ties are resolved in favor of
+// the package name, then of the first name in lexical order. Aliases required by the
+// configuration take precedence, and blank and dot imports are left alone.
+func (g *formatter) consistentAlias(file *ast.File, imp Import) string {
+	if g.config.ConsistentAliases == AliasScopeNone || imp.Name == "_" || imp.Name == "." || g.requiredAlias(imp.Path) != "" {
+		return ""
+	}
+	names := g.aliasCensus(file)[imp.Path]
+	packageName := g.getResolver().PackageName(g.getFilePath(), imp.Path)
+
+	best := ""
+	for name, count := range names {
+		switch {
+		case best == "", count > names[best]:
+			best = name
+		case count < names[best], best == packageName:
+		case name == packageName, name < best:
+			best = name
+		}
+	}
+	if best == "" || best == g.importName(imp) {
+		return ""
+	}
+	return best
+}
+
+// aliasCensus counts the names the files of the scope of a file import each path by,
+// read from disk on first use. Blank, dot and cgo imports are not counted.
+func (g *formatter) aliasCensus(file *ast.File) aliasCensus {
+	filePath, err := filepath.Abs(g.getFilePath())
+	if err != nil {
+		filePath = g.getFilePath()
+	}
+
+	var key string
+	var filePaths []string
+	switch g.config.ConsistentAliases {
+	case AliasScopePackage:
+		key = filepath.Dir(filePath) + "\x00" + file.Name.Name
+		if census, ok := g.censuses[key]; ok {
+			return census
+		}
+		entries, _ := os.ReadDir(filepath.Dir(filePath))
+		for _, entry := range entries {
+			if !entry.IsDir() && utils.IsGoFile(entry.Name()) {
+				filePaths = append(filePaths, filepath.Join(filepath.Dir(filePath), entry.Name()))
+			}
+		}
+	case AliasScopeModule:
+		goMod := utils.FindGoMod(filePath)
+		if goMod == "" {
+			return nil
+		}
+		key = goMod
+		if census, ok := g.censuses[key]; ok {
+			return census
+		}
+		goFiles, _ := utils.FindGoFiles(filepath.Dir(goMod))
+		for _, goFile := range goFiles {
+			if utils.FindGoMod(goFile) == goMod { // Nested modules are left out
+				filePaths = append(filePaths, goFile)
+			}
+		}
+	}
+
+	census := make(aliasCensus)
+	if g.censuses == nil {
+		g.censuses = make(map[string]aliasCensus)
+	}
+	g.censuses[key] = census
+
+	fileSet := token.NewFileSet()
+	for _, path := range filePaths {
+		parsed, err := parser.ParseFile(fileSet, path, nil, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		if g.config.ConsistentAliases == AliasScopePackage && parsed.Name.Name != file.Name.Name {
+			continue
+		}
+
+		counted := make(map[string]bool)
+		for _, spec := range parsed.Imports {
+			importPath := strings.Trim(spec.Path.Value, `"`)
+			name := g.getResolver().PackageName(path, importPath)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			if name == "_" || name == "." || importPath == "C" || counted[importPath] {
+				continue
+			}
+			counted[importPath] = true
+			if census[importPath] == nil {
+				census[importPath] = make(map[string]int)
+			}
+			census[importPath][name]++
+		}
+	}
+	return census
+}
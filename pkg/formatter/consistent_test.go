@@ -0,0 +1,149 @@
+package formatter
+
+import (
+	"go/parser"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/config"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+// newAliasModule creates a module whose files import the same package under several names
+func newAliasModule(t *testing.T) string {
+	t.Helper()
+	return writeModule(t, map[string]string{
+		"go.mod":             "module example.com/app\n\ngo 1.22\n",
+		"api/v1/api.go":      "package api\n\ntype Client struct{}\n",
+		"server/a.go":        "package server\n\nimport apiv1 \"example.com/app/api/v1\"\n\nvar _ apiv1.Client\n",
+		"server/b.go":        "package server\n\nimport apiv1 \"example.com/app/api/v1\"\n\nvar _ apiv1.Client\n",
+		"server/c.go":        "package server\n\nimport pb \"example.com/app/api/v1\"\n\nvar _ pb.Client\n",
+		"server/d_test.go":   "package server_test\n\nimport pb \"example.com/app/api/v1\"\n\nvar _ pb.Client\n",
+		"client/a.go":        "package client\n\nimport \"example.com/app/api/v1\"\n\nvar _ api.Client\n",
+		"client/b.go":        "package client\n\nimport pb \"example.com/app/api/v1\"\n\nvar _ pb.Client\n",
+		"nested/go.mod":      "module example.com/nested\n",
+		"nested/a.go":        "package nested\n\nimport pb \"example.com/app/api/v1\"\n\nvar _ pb.Client\n",
+		"nested/b.go":        "package nested\n\nimport pb \"example.com/app/api/v1\"\n\nvar _ pb.Client\n",
+		"nested/c/c.go":      "package c\n\nimport pb \"example.com/app/api/v1\"\n\nvar _ pb.Client\n",
+		"client/tie/a.go":    "package tie\n\nimport pb \"example.com/app/api/v1\"\n\nvar _ pb.Client\n",
+		"client/tie/b.go":    "package tie\n\nimport apiv1 \"example.com/app/api/v1\"\n\nvar _ apiv1.Client\n",
+		"client/tie/conf.go": "package tie\n\nimport clientv1 \"example.com/app/api/v1\"\n\nvar _ clientv1.Client\n",
+	})
+}
+
+func TestFormatter_ConsistentAliases(t *testing.T) {
+	tests := []struct {
+		name     string
+		scope    AliasScope
+		fileName string
+		aliases  []config.Alias
+		expected string
+	}{
+		{
+			name:     "package majority",
+			scope:    AliasScopePackage,
+			fileName: "server/c.go",
+			expected: "package server\n\nimport (\n\tapiv1 \"example.com/app/api/v1\"\n)\n\nvar _ apiv1.Client\n",
+		},
+		{
+			name:     "consistent file",
+			scope:    AliasScopePackage,
+			fileName: "server/a.go",
+			expected: "package server\n\nimport (\n\tapiv1 \"example.com/app/api/v1\"\n)\n\nvar _ apiv1.Client\n",
+		},
+		{
+			name:     "module majority",
+			scope:    AliasScopeModule,
+			fileName: "server/a.go",
+			expected: "package server\n\nimport (\n\tpb \"example.com/app/api/v1\"\n)\n\nvar _ pb.Client\n",
+		},
+		{
+			name:     "ties favor the package name",
+			scope:    AliasScopePackage,
+			fileName: "client/b.go",
+			expected: "package client\n\nimport (\n\t\"example.com/app/api/v1\"\n)\n\nvar _ api.Client\n",
+		},
+		{
+			name:     "ties favor the first name",
+			scope:    AliasScopePackage,
+			fileName: "client/tie/a.go",
+			expected: "package tie\n\nimport (\n\tapiv1 \"example.com/app/api/v1\"\n)\n\nvar _ apiv1.Client\n",
+		},
+		{
+			name:     "configured aliases take precedence",
+			scope:    AliasScopePackage,
+			fileName: "server/c.go",
+			aliases:  []config.Alias{{Path: "example.com/app/api/*", Alias: "apiclient"}},
+			expected: "package server\n\nimport (\n\tapiclient \"example.com/app/api/v1\"\n)\n\nvar _ apiclient.Client\n",
+		},
+		{
+			name:     "disabled",
+			fileName: "server/c.go",
+			expected: "package server\n\nimport (\n\tpb \"example.com/app/api/v1\"\n)\n\nvar _ pb.Client\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			root := newAliasModule(t)
+			filePath := filepath.Join(root, filepath.FromSlash(tt.fileName))
+			src, err := os.ReadFile(filePath)
+			req.NoError(err)
+
+			g := New(FormatterConfig{FilePath: filePath, CurrentProject: "example.com/app", ConsistentAliases: tt.scope, Aliases: tt.aliases})
+			result, err := g.formatSource(src)
+			req.NoError(err)
+			req.Equal(tt.expected, string(result.output))
+		})
+	}
+}
+
+func TestFormatter_AliasCensus(t *testing.T) {
+	req := require.New(t)
+	root := newAliasModule(t)
+	src := []byte("package server\n")
+
+	g := New(FormatterConfig{FilePath: filepath.Join(root, "server", "a.go"), ConsistentAliases: AliasScopePackage})
+	file, err := parser.ParseFile(g.fileSet, "a.go", src, 0)
+	req.NoError(err)
+	req.Equal(aliasCensus{"example.com/app/api/v1": {"apiv1": 2, "pb": 1}}, g.aliasCensus(file))
+
+	g = New(FormatterConfig{FilePath: filepath.Join(root, "server", "a.go"), ConsistentAliases: AliasScopeModule})
+	req.Equal(aliasCensus{"example.com/app/api/v1": {"apiv1": 3, "pb": 4, "api": 1, "clientv1": 1}}, g.aliasCensus(file))
+}
+
+func TestFormatter_FindViolations_ConsistentAliases(t *testing.T) {
+	req := require.New(t)
+	root := newAliasModule(t)
+	filePath := filepath.Join(root, "server", "c.go")
+	src, err := os.ReadFile(filePath)
+	req.NoError(err)
+
+	g := New(FormatterConfig{FilePath: filePath, CurrentProject: "example.com/app", Check: true, ConsistentAliases: AliasScopePackage})
+	result, err := g.formatSource(src)
+	req.NoError(err)
+	req.Equal(report.StatusChanged, result.status)
+	req.Equal([]report.Violation{{
+		Code:    "GIG016",
+		Rule:    report.RuleInconsistentAlias,
+		Message: `"example.com/app/api/v1" should be imported as apiv1 like in most files of the package`,
+		Line:    3,
+		Column:  8,
+	}}, result.violations)
+}
+
+func TestParseAliasScope(t *testing.T) {
+	req := require.New(t)
+	for _, name := range []string{"", "package", "module"} {
+		scope, err := ParseAliasScope(name)
+		req.NoError(err)
+		req.Equal(AliasScope(name), scope)
+	}
+
+	_, err := ParseAliasScope("workspace")
+	req.ErrorContains(err, `unknown alias scope, expected package or module: "workspace"`)
+}
@@ -0,0 +1,100 @@
+package formatter
+
+import (
+	"go/ast"
+	"strings"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/resolver"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/std"
+)
+
+// deprecatedUse is a use of a deprecated symbol of the standard library in a file
+type deprecatedUse struct {
+	selector  *ast.SelectorExpr
+	migration std.Migration
+}
+
+// deprecatedUses finds the uses of deprecated symbols whose replacement is available
+// in the release of the go directive of the module of the file
+func (g *formatter) deprecatedUses(file *ast.File) []deprecatedUse {
+	paths := make(map[string]string) // import paths by name
+	for _, spec := range file.Imports {
+		importPath := strings.Trim(spec.Path.Value, `"`)
+		if !std.HasMigrations(importPath) {
+			continue
+		}
+		imp := Import{Path: importPath}
+		if spec.Name != nil {
+			imp.Name = spec.Name.Name
+		}
+		if imp.Name != "_" && imp.Name != "." {
+			paths[g.importName(imp)] = importPath
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	goVersion := g.getResolver().GoVersion(g.getFilePath())
+	var uses []deprecatedUse
+	ast.Inspect(file, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := selector.X.(*ast.Ident)
+		if !ok || ident.Obj != nil || paths[ident.Name] == "" {
+			return true
+		}
+		if migration, ok := std.LookupMigration(paths[ident.Name], selector.Sel.Name, goVersion); ok {
+			uses = append(uses, deprecatedUse{selector: selector, migration: migration})
+		}
+		return true
+	})
+	return uses
+}
+
+// migrateDeprecated replaces the uses of deprecated symbols by their replacement, adding
+// the imports of the replacements and removing the deprecated imports left unused. It
+// returns the number of replaced uses. Replacements whose package name is already taken
+// by something else are skipped.
+func (g *formatter) migrateDeprecated(file *ast.File, imports []Import) ([]Import, int) {
+	uses := g.deprecatedUses(file)
+	if len(uses) == 0 {
+		return imports, 0
+	}
+
+	migrated := make(map[string]bool) // deprecated import paths having replaced uses
+	replaced := 0
+	for _, use := range uses {
+		name := ""
+		for _, imp := range imports {
+			if imp.Path == use.migration.NewPath && imp.Name != "_" && imp.Name != "." {
+				name = g.importName(imp)
+				break
+			}
+		}
+		if name == "" {
+			name = resolver.AssumedName(use.migration.NewPath)
+			if g.isNameTaken(file, imports, name) {
+				continue
+			}
+			imports = append(imports, Import{Path: use.migration.NewPath})
+		}
+
+		use.selector.X.(*ast.Ident).Name = name
+		use.selector.Sel.Name = use.migration.NewSymbol
+		migrated[use.migration.Path] = true
+		replaced++
+	}
+
+	qualifiers := usedQualifiers(file)
+	var kept []Import
+	for _, imp := range imports {
+		if migrated[imp.Path] && !qualifiers[g.importName(imp)] {
+			continue
+		}
+		kept = append(kept, imp)
+	}
+	return kept, replaced
+}
@@ -0,0 +1,157 @@
+package formatter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+func TestFormatter_MigrateDeprecated(t *testing.T) {
+	tests := []struct {
+		name      string
+		goVersion string
+		src       string
+		expected  string
+	}{
+		{
+			name:      "ioutil is replaced",
+			goVersion: "1.21",
+			src: `package main
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	data, _ := ioutil.ReadAll(os.Stdin)
+	_ = ioutil.WriteFile("a", data, 0644)
+	_, _ = io.Copy(ioutil.Discard, os.Stdin)
+}
+`,
+			expected: `package main
+
+import (
+	"io"
+	"os"
+)
+
+func main() {
+	data, _ := io.ReadAll(os.Stdin)
+	_ = os.WriteFile("a", data, 0644)
+	_, _ = io.Copy(io.Discard, os.Stdin)
+}
+`,
+		},
+		{
+			name:      "symbols without replacement keep the import",
+			goVersion: "1.21",
+			src: `package main
+
+import ioutil2 "io/ioutil"
+
+func main() {
+	_, _ = ioutil2.ReadDir(".")
+	_, _ = ioutil2.ReadFile("a")
+}
+`,
+			expected: `package main
+
+import (
+	ioutil2 "io/ioutil"
+	"os"
+)
+
+func main() {
+	_, _ = ioutil2.ReadDir(".")
+	_, _ = os.ReadFile("a")
+}
+`,
+		},
+		{
+			name:      "replacements newer than the go directive are not used",
+			goVersion: "1.15",
+			src: `package main
+
+import (
+	"io/ioutil"
+	"reflect"
+)
+
+var _, _ = ioutil.ReadFile("a")
+var _ = reflect.Ptr
+`,
+			expected: `package main
+
+import (
+	"io/ioutil"
+	"reflect"
+)
+
+var _, _ = ioutil.ReadFile("a")
+var _ = reflect.Ptr
+`,
+		},
+		{
+			name:      "taken package names are not imported",
+			goVersion: "1.21",
+			src: `package main
+
+import (
+	"io/ioutil"
+	"reflect"
+)
+
+var os = "linux"
+var _, _ = ioutil.ReadFile(os)
+var _ = reflect.Ptr
+`,
+			expected: `package main
+
+import (
+	"io/ioutil"
+	"reflect"
+)
+
+var os = "linux"
+var _, _ = ioutil.ReadFile(os)
+var _ = reflect.Pointer
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			dir := t.TempDir()
+			req.NoError(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/test/project\n\ngo "+tt.goVersion+"\n"), 0644))
+
+			g := New(FormatterConfig{FilePath: filepath.Join(dir, "main.go"), CurrentProject: "github.com/test/project", MigrateDeprecated: true})
+			result, err := g.formatSource([]byte(tt.src))
+			req.NoError(err)
+			req.Equal(tt.expected, string(result.output))
+		})
+	}
+}
+
+func TestFormatter_FindViolations_Deprecated(t *testing.T) {
+	req := require.New(t)
+	src := "package main\n\nimport (\n\t\"io/ioutil\"\n)\n\nvar _, _ = ioutil.ReadFile(\"a\")\n"
+
+	g := New(FormatterConfig{FilePath: filepath.Join(t.TempDir(), "main.go"), CurrentProject: "github.com/test/project", Check: true, MigrateDeprecated: true})
+	result, err := g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Equal([]report.Violation{{
+		Code:    "GIG013",
+		Rule:    report.RuleDeprecatedSymbol,
+		Message: "io/ioutil.ReadFile is deprecated, use os.ReadFile",
+		Line:    7,
+		Column:  12,
+	}}, result.violations)
+	req.Nil(result.fix)
+}
@@ -1,39 +1,86 @@
 package formatter
 
 import (
+	"bytes"
 	"fmt"
 	"go/ast"
+	"go/build"
 	"go/format"
 	"go/parser"
 	"go/token"
+	"io"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/siyuan-infoblox/go-imports-group/pkg/config"
 	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/journal"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/resolver"
 	"github.com/siyuan-infoblox/go-imports-group/pkg/std"
 	"github.com/siyuan-infoblox/go-imports-group/pkg/utils"
 )
 
 type FormatterConfig struct {
-	FilePath       string   // path to the Go source file
-	Orgs           []string // organization prefixes to group imports by
-	CurrentProject string   // optional current project override
-	InPlace        bool     // whether to modify the file in place
+	FilePath          string              // path to the Go source file
+	Orgs              []string            // organization prefixes to group imports by
+	CurrentProject    string              // optional current project override
+	InPlace           bool                // whether to modify the file in place
+	ChangedSince      string              // only process Go files changed since this git ref
+	Staged            bool                // only process Go files staged in the git index
+	LineRanges        []LineRange         // only rewrite the file if its imports intersect these line ranges
+	BuildContext      *build.Context      // only process files matching this build context, nil for all files
+	FollowSymlinks    bool                // follow symlinked directories when walking a directory
+	Format            report.Format       // output format of the run, text when empty
+	Output            io.Writer           // destination of the output, stdout when nil
+	Check             bool                // report misgrouped imports instead of rewriting them
+	Disable           []string            // codes or IDs of the violation rules not reported in check mode
+	RemoveUnused      bool                // remove the imports that are not referenced by the file
+	AddMissing        bool                // add the imports of the qualifiers that resolve to a single package
+	RemoveAliases     bool                // remove the aliases equal to the name of the imported package
+	ConsistentAliases AliasScope          // rename the imports named differently than in most files of the scope
+	Aliases           []config.Alias      // aliases required for import paths, renaming the uses of the imports
+	Rules             []config.Rule       // imports denied or allowed in the files matching patterns
+	DotImports        config.ImportPolicy // where dot imports are allowed, the others are converted to named imports
+	BlankImports      config.ImportPolicy // where blank imports are allowed
+	Sort              map[string][]string // sort keys of the imports of each group, by group name, path when not set
+	ReplaceDenied     bool                // rewrite the denied imports that have a replacement
+	Rewrites          []Rewrite           // import paths to rewrite, only the files importing them are processed
+	MigrateDeprecated bool                // replace the deprecated symbols of the standard library by their replacement
+	BackupSuffix      string              // keep the original of files modified in place with this suffix, none when empty
+	Journal           *journal.Recorder   // records the files modified in place so that the run can be undone, nil for none
+
+	// ResolveModule resolves the project module of a file when CurrentProject is empty,
+	// utils.GetProjectModule when nil. It lets long-running callers cache the lookups.
+	ResolveModule func(filePath string) string
+
+	// Resolver looks up packages on disk for AddMissing and RemoveAliases, created on first use when nil.
+	// It caches the lookups, long-running callers can share it across runs.
+	Resolver *resolver.Resolver
 }
 
 // formatter handles the import grouping logic
 type formatter struct {
-	config  FormatterConfig
-	fileSet *token.FileSet
+	config   FormatterConfig
+	fileSet  *token.FileSet
+	reporter report.Reporter
+	scopes   map[string]*packageScope // declarations of the other files of a package, by directory
+	censuses map[string]aliasCensus   // names of the imports of the files of a package or module
 }
 
 // New creates a new Formatter with the specified organization prefixes and optional current project
 func New(config FormatterConfig) *formatter {
-	return &formatter{
+	g := &formatter{
 		config:  config,
 		fileSet: token.NewFileSet(),
 	}
+	g.reporter = report.New(g.getFormat(), g.out(), report.Options{
+		InPlace: g.getInPlace(),
+		Check:   g.getCheck(),
+	})
+	return g
 }
 
 func (g *formatter) getFilePath() string {
@@ -47,6 +94,9 @@ func (g *formatter) getOrgs() []string {
 func (g *formatter) getCurrentProject() string {
 	if g.config.CurrentProject == "" {
 		// If no current project is specified, try to infer it from the file path
+		if g.config.ResolveModule != nil {
+			return g.config.ResolveModule(g.getFilePath())
+		}
 		return utils.GetProjectModule(g.getFilePath())
 	}
 	return g.config.CurrentProject
@@ -56,28 +106,75 @@ func (g *formatter) getInPlace() bool {
 	return g.config.InPlace
 }
 
-// extractImports extracts import information from the AST
-func (g *formatter) extractImports(file *ast.File) []Import {
-	var imports []Import
-	seen := make(map[string]bool) // Track which paths we've seen
+func (g *formatter) getCheck() bool {
+	return g.config.Check
+}
 
-	for _, importSpec := range file.Imports {
-		path := strings.Trim(importSpec.Path.Value, `"`)
+// filterBuildFiles limits the files to the ones matching the configured build context
+func (g *formatter) filterBuildFiles(filePaths []string) []string {
+	if g.config.BuildContext == nil {
+		return filePaths
+	}
+	return utils.FilterBuildFiles(filePaths, g.config.BuildContext)
+}
 
-		// Skip if we've already seen this path
-		if seen[path] {
-			continue
-		}
-		seen[path] = true
+func (g *formatter) getFormat() report.Format {
+	if g.config.Format == "" {
+		return report.FormatText
+	}
+	return g.config.Format
+}
+
+func (g *formatter) isTextFormat() bool {
+	return g.getFormat() == report.FormatText
+}
 
+// out returns the destination of the output
+func (g *formatter) out() io.Writer {
+	if g.config.Output == nil {
+		return os.Stdout
+	}
+	return g.config.Output
+}
+
+// infof prints free-text progress messages, which are only part of the text format
+func (g *formatter) infof(format string, args ...any) {
+	if g.isTextFormat() {
+		fmt.Fprintf(g.out(), format, args...)
+	}
+}
+
+func (g *formatter) isGitMode() bool {
+	return g.config.Staged || g.config.ChangedSince != ""
+}
+
+// extractImports extracts import information from the AST, applying the rewrites and the
+// replacements of denied imports. An import rewritten to a path the file already imports
+// is merged into that import, renaming its uses. It returns the number of renamed imports.
+func (g *formatter) extractImports(file *ast.File) ([]Import, int) {
+	var imports []Import
+	rewritten := make(map[int]bool) // indexes of the rewritten imports
+
+	for _, importSpec := range file.Imports {
 		imp := Import{
-			Path: path,
+			Path: strings.Trim(importSpec.Path.Value, `"`),
 		}
 
 		if importSpec.Name != nil {
 			imp.Name = importSpec.Name.Name
 		}
 
+		previousPath := imp.Path
+		if newPath, ok := g.rewrittenPath(imp.Path); ok {
+			imp = g.rewriteImportPath(imp, newPath)
+		}
+		if g.config.ReplaceDenied {
+			if denial := g.deniedImport(imp.Path); denial != nil && denial.replacement != "" {
+				imp = g.rewriteImportPath(imp, denial.replacement)
+			}
+		}
+		rewritten[len(imports)] = imp.Path != previousPath
+
 		if importSpec.Comment != nil {
 			imp.Comment = importSpec.Comment.Text()
 		}
@@ -85,7 +182,41 @@ func (g *formatter) extractImports(file *ast.File) []Import {
 		imports = append(imports, imp)
 	}
 
-	return imports
+	// Imports of the file are kept first, duplicates are skipped and the rewritten
+	// imports of a path already imported are merged into it
+	var kept []Import
+	byPath := make(map[string]int) // index in kept
+	renamed := 0
+	for _, rewrittenPass := range []bool{false, true} {
+		for i, imp := range imports {
+			if rewritten[i] != rewrittenPass {
+				continue
+			}
+			j, ok := byPath[imp.Path]
+			if !ok {
+				byPath[imp.Path] = len(kept)
+				kept = append(kept, imp)
+				continue
+			}
+
+			existing := kept[j]
+			switch {
+			case !rewritten[i], imp.Name == "_":
+				// Duplicate or blank import, nothing refers to it
+			case existing.Name == "_":
+				kept[j] = imp // The package is still initialized through the rewritten import
+			case imp.Name == "." || existing.Name == ".":
+				kept = append(kept, imp) // Unqualified uses cannot be renamed, the path is imported twice
+			default:
+				if name := g.importName(existing); name != g.importName(imp) {
+					renameQualifier(file, g.importName(imp), name)
+					renamed++
+				}
+			}
+		}
+	}
+
+	return kept, renamed
 }
 
 // groupImports categorizes imports into different groups
@@ -161,19 +292,26 @@ func (g *formatter) getOrgInfo(importPath string) (int, string) {
 
 // sortImportsInGroup sorts imports within a group
 func (g *formatter) sortImportsInGroup(imports []Import, group ImportGroup) {
-	if group >= OrgGroupBase {
-		// Sort org imports by project name, then alphabetically
-		sort.Slice(imports, func(i, j int) bool {
-			if imports[i].ProjectName != imports[j].ProjectName {
-				return imports[i].ProjectName < imports[j].ProjectName
+	less := g.importLess(group)
+	sort.Slice(imports, func(i, j int) bool {
+		return less(imports[i], imports[j])
+	})
+}
+
+// importLess returns the ordering of imports within a group: by the configured sort
+// keys, then alphabetically. Org imports are sorted by project name first.
+func (g *formatter) importLess(group ImportGroup) func(a, b Import) bool {
+	keys := g.sortKeys(group)
+	return func(a, b Import) bool {
+		if group >= OrgGroupBase && a.ProjectName != b.ProjectName {
+			return a.ProjectName < b.ProjectName
+		}
+		for _, key := range keys {
+			if c := g.compareImports(key, a, b); c != 0 {
+				return c < 0
 			}
-			return imports[i].Path < imports[j].Path
-		})
-	} else {
-		// Sort alphabetically
-		sort.Slice(imports, func(i, j int) bool {
-			return imports[i].Path < imports[j].Path
-		})
+		}
+		return a.Path < b.Path
 	}
 }
 
@@ -181,12 +319,16 @@ func (g *formatter) sortImportsInGroup(imports []Import, group ImportGroup) {
 func (g *formatter) replaceImports(file *ast.File, groupedImports map[ImportGroup][]Import) *ast.File {
 	// Remove existing imports
 	var newDecls []ast.Decl
+	var importDecls []*ast.GenDecl
 	for _, decl := range file.Decls {
 		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			importDecls = append(importDecls, genDecl)
 			continue // Skip import declarations
 		}
 		newDecls = append(newDecls, decl)
 	}
+	// Drop the comments of the removed imports, they are carried by the Import entries
+	file.Comments = withoutImportComments(file.Comments, importDecls)
 
 	// Create new import declaration
 	if hasImports := len(groupedImports[StdGroup]) > 0 ||
@@ -316,12 +458,14 @@ func (g *formatter) formatFile(file *ast.File) ([]byte, error) {
 	originalImports := file.Imports
 	originalDecls := file.Decls
 	var importDecl *ast.GenDecl
+	var importDecls []*ast.GenDecl
 	var nonImportDecls []ast.Decl
 
 	// Find and separate import declarations from other declarations
 	for _, decl := range file.Decls {
 		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
 			importDecl = genDecl
+			importDecls = append(importDecls, genDecl)
 		} else {
 			nonImportDecls = append(nonImportDecls, decl)
 		}
@@ -329,9 +473,10 @@ func (g *formatter) formatFile(file *ast.File) ([]byte, error) {
 
 	file.Imports = nil
 	file.Decls = nonImportDecls
-	// Clear any comments that might be orphaned from import removal
+	// Drop comments that would be orphaned from import removal, while keeping
+	// the rest such as build constraints above the package clause
 	originalComments := file.Comments
-	file.Comments = nil
+	file.Comments = withoutImportComments(file.Comments, importDecls)
 
 	// Format the file without imports
 	var buf strings.Builder
@@ -360,32 +505,78 @@ func (g *formatter) formatFile(file *ast.File) ([]byte, error) {
 		result = append(result, line)
 		if !packageLineFound && strings.HasPrefix(strings.TrimSpace(line), "package ") {
 			packageLineFound = true
+			if importDecl == nil {
+				continue // All imports were removed
+			}
 			result = append(result, "") // Add blank line after package
 
 			// Add custom formatted imports
-			if importDecl != nil && len(importDecl.Specs) > 0 {
-				result = append(result, "import (")
+			result = append(result, g.formatImportDecl(importDecl)...)
+		}
+	}
+
+	return []byte(strings.Join(result, "\n")), nil
+}
+
+// firstImportDecl returns the import declaration of a file, nil when it has none
+func firstImportDecl(file *ast.File) *ast.GenDecl {
+	if len(file.Decls) == 0 {
+		return nil
+	}
+	if genDecl, ok := file.Decls[0].(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+		return genDecl
+	}
+	return nil
+}
 
-				// Format each import spec preserving the order from replaceImports
-				for i, spec := range importDecl.Specs {
-					if importSpec, ok := spec.(*ast.ImportSpec); ok {
-						importLine := g.formatImportSpec(importSpec)
+// formatImportDecl formats an import declaration into lines, with blank lines between groups
+func (g *formatter) formatImportDecl(importDecl *ast.GenDecl) []string {
+	if importDecl == nil || len(importDecl.Specs) == 0 {
+		return nil
+	}
 
-						// Add spacing based on group changes
-						if i > 0 && g.shouldAddSpacingBetweenImports(importDecl.Specs, i) {
-							result = append(result, "")
-						}
+	result := []string{"import ("}
 
-						result = append(result, "\t"+importLine)
-					}
-				}
+	// Format each import spec preserving the order from replaceImports
+	for i, spec := range importDecl.Specs {
+		if importSpec, ok := spec.(*ast.ImportSpec); ok {
+			importLine := g.formatImportSpec(importSpec)
 
-				result = append(result, ")")
+			// Add spacing based on group changes
+			if i > 0 && g.shouldAddSpacingBetweenImports(importDecl.Specs, i) {
+				result = append(result, "")
 			}
+
+			result = append(result, "\t"+importLine)
 		}
 	}
 
-	return []byte(strings.Join(result, "\n")), nil
+	return append(result, ")")
+}
+
+// withoutImportComments returns the comment groups that are not part of the given import declarations
+func withoutImportComments(comments []*ast.CommentGroup, importDecls []*ast.GenDecl) []*ast.CommentGroup {
+	var result []*ast.CommentGroup
+	for _, comment := range comments {
+		inImportDecl := false
+		for _, decl := range importDecls {
+			if !decl.Pos().IsValid() {
+				continue // Generated declaration without source positions
+			}
+			start := decl.Pos()
+			if decl.Doc != nil {
+				start = decl.Doc.Pos()
+			}
+			if comment.Pos() >= start && comment.End() <= decl.End() {
+				inImportDecl = true
+				break
+			}
+		}
+		if !inImportDecl {
+			result = append(result, comment)
+		}
+	}
+	return result
 }
 
 // formatImportSpec formats a single import spec
@@ -451,51 +642,275 @@ func (g *formatter) shouldAddSpacingBetweenImports(specs []ast.Spec, currentInde
 	return false
 }
 
-// ProcessFileWithOutput processes a Go source file with optional output control
-func (g *formatter) ProcessFileWithOutput(verbose bool) error {
+// formatResult holds the outcome of formatting a single source file
+type formatResult struct {
+	output  []byte                   // resulting source
+	status  report.Status            // whether the source was changed
+	grouped map[ImportGroup][]Import // grouped imports, nil when the imports were not grouped
+
+	violations []report.Violation // violations found in check mode
+	warnings   []report.Violation // issues left unfixed, such as ambiguous missing imports
+	fix        *report.Fix        // replacement of the import block, nil when compliant in check mode
+}
+
+// formatSource groups the imports of Go source code held in memory
+func (g *formatter) formatSource(src []byte) (*formatResult, error) {
+	file, err := parser.ParseFile(g.fileSet, g.getFilePath(), src, parser.ParseComments)
+	if err != nil {
+		return nil, errors.NewParseError(g.getFilePath(), err)
+	}
+
+	var missing []missingImport
+	if g.config.AddMissing {
+		missing = g.findMissingImports(file)
+	}
+
+	if len(file.Imports) == 0 && len(missing) == 0 {
+		// No imports to process
+		return &formatResult{output: src, status: report.StatusUnchanged}, nil
+	}
+
+	if len(g.config.Rewrites) > 0 && !g.hasRewrites(file) {
+		// Rewrites only modify the files importing the rewritten paths
+		return &formatResult{output: src, status: report.StatusUnchanged}, nil
+	}
+
+	if !g.intersectsLineRanges(file) {
+		// Import declarations are outside of the requested line ranges
+		return &formatResult{output: src, status: report.StatusSkipped}, nil
+	}
+
+	var violations []report.Violation
+	if g.getCheck() {
+		// Violations refer to the original positions, so they are found before rewriting
+		violations = g.findViolations(src, file, missing)
+	}
+	startLine, endLine, hasImportDecl := importDeclLines(g.fileSet, file)
+
+	imports, renamed := g.extractImports(file)
+	if g.config.RemoveUnused {
+		imports = g.removeUnused(file, imports)
+	}
+	if g.config.RemoveAliases {
+		imports = g.removeRedundantAliases(imports)
+	}
+	imports = addMissing(imports, missing)
+	converted, warnings := g.applyImportPolicies(file, imports)
+	renamed += converted
+	aliased, aliasWarnings := g.enforceAliases(file, imports)
+	renamed += aliased
+	warnings = append(warnings, aliasWarnings...)
+	if g.config.MigrateDeprecated {
+		var replaced int
+		imports, replaced = g.migrateDeprecated(file, imports)
+		renamed += replaced
+	}
+	groupedImports := g.groupImports(imports, g.getFilePath())
+	newFile := g.replaceImports(file, groupedImports)
+
+	output, err := g.formatFile(newFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errors.ErrMsgFailedToFormatFile, err)
+	}
+
+	result := &formatResult{
+		output:  output,
+		status:  report.StatusChanged,
+		grouped: groupedImports,
+	}
+	if hasImportDecl && renamed == 0 {
+		// Files without an import declaration have no lines to replace, and renamed
+		// imports or replaced symbols change lines outside of the declaration
+		result.fix = &report.Fix{
+			StartLine:   startLine,
+			EndLine:     endLine,
+			Replacement: strings.Join(g.formatImportDecl(firstImportDecl(newFile)), "\n"),
+		}
+	}
+	if g.getCheck() {
+		// In check mode a file only needs changes when its imports are misgrouped
+		if len(violations) == 0 {
+			result.status = report.StatusUnchanged
+			result.fix = nil
+			return result, nil
+		}
+		result.violations = violations
+		return result, nil
+	}
+	for _, m := range missing {
+		if len(m.candidates) > 1 {
+			result.warnings = append(result.warnings, ambiguousImportViolation(m))
+		}
+	}
+	result.warnings = append(result.warnings, warnings...)
+	result.warnings = append(result.warnings, g.deniedImportWarnings(file)...)
+	if bytes.Equal(output, src) {
+		result.status = report.StatusUnchanged
+	}
+	return result, nil
+}
+
+// processFile processes a Go source file and returns the formatting result
+func (g *formatter) processFile(verbose bool) (*formatResult, error) {
 	if verbose {
-		fmt.Print(errors.InfoMsgCurrentProjectOutput, g.getCurrentProject(), "\n")
+		fmt.Fprint(g.out(), errors.InfoMsgCurrentProjectOutput, g.getCurrentProject(), "\n")
 	}
 	src, err := os.ReadFile(g.getFilePath())
 	if err != nil {
-		return fmt.Errorf("%s: %w", errors.ErrMsgFailedToReadFile, err)
+		return nil, &errors.ReadError{Path: g.getFilePath(), Err: err}
 	}
 
-	file, err := parser.ParseFile(g.fileSet, g.getFilePath(), src, parser.ParseComments)
+	result, err := g.formatSource(src)
 	if err != nil {
-		return fmt.Errorf("%s: %w", errors.ErrMsgFailedToParseFile, err)
+		return nil, err
+	}
+	if verbose {
+		// Single files are not reported as records, their warnings are printed instead
+		for _, warning := range result.warnings {
+			fmt.Fprintf(g.out(), errors.InfoMsgViolation+"\n", g.getFilePath(), warning.Line, warning.Column, warning.Code, warning.Message, warning.Rule)
+		}
 	}
 
-	if len(file.Imports) == 0 {
-		// No imports to process
-		if g.getInPlace() {
-			return nil
+	if g.getCheck() {
+		// Check mode never modifies files nor prints their content
+		return result, nil
+	}
+
+	if g.getInPlace() {
+		if result.status != report.StatusChanged {
+			return result, nil
 		}
-		if !g.getInPlace() && verbose {
-			fmt.Print(string(src))
+		if err := g.writeFile(src, result.output); err != nil {
+			return nil, &errors.WriteError{Path: g.getFilePath(), Err: err}
 		}
+		return result, nil
+	}
+
+	if verbose {
+		// For stdout output, show the complete formatted file
+		fmt.Fprint(g.out(), string(result.output))
+	}
+	return result, nil
+}
+
+// writeFile replaces the content of the current file, keeping a backup and recording
+// the original in the journal first when configured
+func (g *formatter) writeFile(src, output []byte) error {
+	path := g.getFilePath()
+	if bytes.Equal(src, output) {
 		return nil
 	}
 
-	imports := g.extractImports(file)
-	groupedImports := g.groupImports(imports, g.getFilePath())
-	newFile := g.replaceImports(file, groupedImports)
+	var backup string
+	if g.config.BackupSuffix != "" {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		backup = path + g.config.BackupSuffix
+		created, err := utils.CreateFileAtomic(backup, src, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if !created {
+			backup = "" // The backup of an earlier run holds an older original, it is kept
+		} else if backup, err = filepath.Abs(backup); err != nil {
+			return err
+		}
+	}
+	if g.config.Journal != nil {
+		// Journaling is best-effort, e.g. without a writable cache directory the files
+		// are still written but the run cannot be undone
+		if err := g.config.Journal.Record(path, src, output, backup); err != nil {
+			g.infof(errors.WarnMsgJournalFailed+"\n", err)
+			g.config.Journal = nil
+		}
+	}
+
+	_, err := utils.WriteFileAtomic(path, output)
+	return err
+}
 
-	var output []byte
-	output, err = g.formatFile(newFile)
+// newFileRecord describes the outcome of processing the current file for reporting
+func (g *formatter) newFileRecord(result *formatResult, err error) report.FileRecord {
+	record := report.FileRecord{
+		Path:   g.getFilePath(),
+		Module: g.getCurrentProject(),
+	}
 	if err != nil {
-		return fmt.Errorf("%s: %w", errors.ErrMsgFailedToFormatFile, err)
+		record.Status = report.StatusError
+		record.Error = report.NewErrorRecord(err)
+		return record
 	}
 
-	if g.getInPlace() {
-		return os.WriteFile(g.getFilePath(), output, 0644)
+	record.Status = result.status
+	record.Warnings = result.warnings
+	if g.getCheck() {
+		record.Violations = result.violations
+		record.Fix = result.fix
 	}
+	if result.grouped != nil {
+		record.Groups = make(map[string]int)
+		for group, imports := range result.grouped {
+			record.Groups[g.groupName(group)] = len(imports)
+		}
+	}
+	return record
+}
 
-	if verbose {
-		// For stdout output, show the complete formatted file
-		fmt.Print(string(output))
+// groupName returns the name of an import group used in reports
+func (g *formatter) groupName(group ImportGroup) string {
+	switch group {
+	case StdGroup:
+		return "std"
+	case ThirdPartyGroup:
+		return "third-party"
+	case ProjectGroup:
+		return "project"
 	}
-	return nil
+	if orgIndex := int(group - OrgGroupBase); orgIndex >= 0 && orgIndex < len(g.getOrgs()) {
+		return "org:" + g.getOrgs()[orgIndex]
+	}
+	return fmt.Sprintf("group:%d", group)
+}
+
+// CheckSource finds the violations of Go source code held in memory, as if it was
+// read from path. The record holds the violations and the fix of the import block.
+func (g *formatter) CheckSource(path string, src []byte) (report.FileRecord, error) {
+	g.config.FilePath = path
+	check := g.config.Check
+	g.config.Check = true
+	defer func() { g.config.Check = check }()
+
+	result, err := g.formatSource(src)
+	return g.newFileRecord(result, err), err
+}
+
+// FormatImports groups the imports of Go source code held in memory, as if it was read
+// from path. It returns the replacement of the lines of the import block, nil when the
+// imports are already grouped.
+func (g *formatter) FormatImports(path string, src []byte) (*report.Fix, error) {
+	g.config.FilePath = path
+	check := g.config.Check
+	g.config.Check = false
+	defer func() { g.config.Check = check }()
+
+	result, err := g.formatSource(src)
+	if err != nil || result.fix == nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(src), "\n")
+	if strings.Join(lines[result.fix.StartLine-1:result.fix.EndLine], "\n") == result.fix.Replacement {
+		return nil, nil
+	}
+	return result.fix, nil
+}
+
+// ProcessFileWithOutput processes a Go source file with optional output control
+func (g *formatter) ProcessFileWithOutput(verbose bool) error {
+	_, err := g.processFile(verbose)
+	return err
 }
 
 // ProcessFile processes a Go source file and groups its imports
@@ -505,36 +920,78 @@ func (g *formatter) ProcessFile() error {
 
 // ProcessFiles processes multiple Go source files and groups their imports
 func (g *formatter) ProcessFiles(filePaths []string) error {
-	processedCount := 0
-	errorCount := 0
+	var summary report.Summary
+	var fileErrors []error
 
 	for _, filePath := range filePaths {
 		g.config.FilePath = filePath
-		if err := g.ProcessFileWithOutput(false); err != nil {
-			fmt.Printf(errors.InfoMsgErrorProcessing+"\n", filePath, err)
-			errorCount++
-		} else {
-			processedCount++
-			if g.getInPlace() {
-				fmt.Printf(errors.InfoMsgProcessedFiles+"\n", filePath)
-			}
+		result, err := g.processFile(false)
+		if err != nil {
+			fileErrors = append(fileErrors, err)
+		}
+
+		record := g.newFileRecord(result, err)
+		summary.Add(record)
+		if err := g.reporter.File(record); err != nil {
+			return err
 		}
 	}
 
-	fmt.Printf(errors.InfoMsgProcessedCount, processedCount)
-	if errorCount > 0 {
-		fmt.Printf(errors.InfoMsgErrorCount, errorCount)
+	if err := g.reporter.Close(summary); err != nil {
+		return err
 	}
-	fmt.Println()
 
-	if errorCount > 0 {
-		return fmt.Errorf(errors.ErrMsgFilesFailedToProcess, errorCount)
+	if len(fileErrors) > 0 {
+		return &errors.MultiError{Errors: fileErrors}
+	}
+	if g.getCheck() && summary.Changed > 0 {
+		return fmt.Errorf(errors.ErrMsgCheckFailed, summary.Changed)
 	}
 	return nil
 }
 
+// ProcessChangedFiles processes the Go files under a file or directory path
+// that are staged or changed since the configured git ref
+func (g *formatter) ProcessChangedFiles(path string) error {
+	var goFiles []string
+	var err error
+	if g.config.Staged {
+		goFiles, err = utils.FindStagedGoFiles(path)
+	} else {
+		goFiles, err = utils.FindChangedGoFiles(path, g.config.ChangedSince)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", errors.ErrMsgFailedToFindGitFiles, err)
+	}
+	goFiles = g.filterBuildFiles(goFiles)
+
+	if !g.getInPlace() && !g.getCheck() {
+		g.infof(errors.WarnMsgProcessingDirWithoutInPlace + "\n")
+		g.infof(errors.InfoMsgUseInPlaceFlag + "\n\n")
+	}
+
+	if len(goFiles) == 0 {
+		g.infof(errors.InfoMsgNoChangedGoFiles+"\n", path)
+		if g.isTextFormat() {
+			return nil
+		}
+	} else {
+		g.infof(errors.InfoMsgFoundChangedGoFiles+"\n", len(goFiles), path)
+		if g.getCurrentProject() != "" {
+			g.infof(errors.InfoMsgCurrentProject+"\n", g.getCurrentProject())
+		}
+		g.infof("\n")
+	}
+
+	return g.ProcessFiles(goFiles)
+}
+
 // ProcessPath processes a file or directory path
 func (g *formatter) ProcessPath(path string) error {
+	if g.isGitMode() {
+		return g.ProcessChangedFiles(path)
+	}
+
 	isDir, err := utils.IsDirectory(path)
 	if err != nil {
 		return fmt.Errorf("%s: %w", errors.ErrMsgFailedToCheckPath, err)
@@ -542,31 +999,43 @@ func (g *formatter) ProcessPath(path string) error {
 
 	if isDir {
 		// When processing directories, in-place mode is recommended
-		if !g.getInPlace() {
-			fmt.Printf(errors.WarnMsgProcessingDirWithoutInPlace + "\n")
-			fmt.Printf(errors.InfoMsgUseInPlaceFlag + "\n\n")
+		if !g.getInPlace() && !g.getCheck() {
+			g.infof(errors.WarnMsgProcessingDirWithoutInPlace + "\n")
+			g.infof(errors.InfoMsgUseInPlaceFlag + "\n\n")
 		}
 
 		// Find all Go files in the directory
-		goFiles, err := utils.FindGoFiles(path)
+		goFiles, err := utils.FindGoFilesWithOptions(path, utils.FindOptions{
+			FollowSymlinks: g.config.FollowSymlinks,
+		})
 		if err != nil {
 			return fmt.Errorf("%s: %w", errors.ErrMsgFailedToFindGoFiles, err)
 		}
+		goFiles = g.filterBuildFiles(goFiles)
 
 		if len(goFiles) == 0 {
-			fmt.Printf(errors.InfoMsgNoGoFilesFound+"\n", path)
-			return nil
-		}
-
-		fmt.Printf(errors.InfoMsgFoundGoFiles+"\n", len(goFiles), path)
-		if g.getCurrentProject() != "" {
-			fmt.Printf(errors.InfoMsgCurrentProject+"\n", g.getCurrentProject())
+			g.infof(errors.InfoMsgNoGoFilesFound+"\n", path)
+			if g.isTextFormat() {
+				return nil
+			}
+		} else {
+			g.infof(errors.InfoMsgFoundGoFiles+"\n", len(goFiles), path)
+			if g.getCurrentProject() != "" {
+				g.infof(errors.InfoMsgCurrentProject+"\n", g.getCurrentProject())
+			}
+			g.infof("\n")
 		}
-		fmt.Println()
 
 		return g.ProcessFiles(goFiles)
+	} else if !g.isTextFormat() || g.getCheck() {
+		// Structured formats and check mode report the file instead of printing its content
+		return g.ProcessFiles([]string{path})
 	} else {
 		g.config.FilePath = path
-		return g.ProcessFile()
+		result, err := g.processFile(true)
+		if err == nil && g.getInPlace() && result.status == report.StatusSkipped {
+			g.infof(errors.InfoMsgUntouchedFiles+"\n", path)
+		}
+		return err
 	}
 }
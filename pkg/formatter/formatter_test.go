@@ -1,17 +1,35 @@
 package formatter
 
 import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/journal"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/testutil"
 )
 
+// writeModule writes files into a new module root, with an empty module cache, and returns the root
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	t.Setenv("GOMODCACHE", t.TempDir())
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, files)
+	return root
+}
+
 func TestFormatter_isStdImport(t *testing.T) {
 	req := require.New(t)
 	g := New(FormatterConfig{
@@ -207,6 +225,7 @@ func main() {
 `
 	testFile := filepath.Join(tempDir, "main.go")
 	req.NoError(os.WriteFile(testFile, []byte(testGoContent), 0644))
+	req.NoError(os.Chmod(testFile, 0600))
 
 	orgs := []string{"github.com/myorg"}
 	g := New(FormatterConfig{
@@ -230,6 +249,11 @@ func main() {
 		req.Contains(processedStr, `"fmt"`)
 		req.Contains(processedStr, `"strings"`)
 		req.Contains(processedStr, `"github.com/external/lib"`)
+
+		// The file is replaced atomically, keeping its mode
+		info, err := os.Stat(testFile)
+		req.NoError(err)
+		req.Equal(os.FileMode(0600), info.Mode().Perm())
 	})
 
 	t.Run("process file without imports", func(t *testing.T) {
@@ -287,7 +311,7 @@ import (
 	astFile, err := parseString(testContent)
 	req.NoError(err)
 
-	imports := g.extractImports(astFile)
+	imports, _ := g.extractImports(astFile)
 
 	req.Len(imports, 4)
 
@@ -688,6 +712,61 @@ func main() {
 	req.Contains(resultStr, "package test", "Formatted result should contain package declaration")
 }
 
+func TestFormatter_ProcessFile_PreservesBuildConstraints(t *testing.T) {
+	req := require.New(t)
+	testGoContent := `// Copyright 2024 Acme Corp.
+
+//go:build linux && !cgo
+// +build linux,!cgo
+
+// Package main is an example.
+package main
+
+// Imports of the example
+import (
+	"github.com/external/lib"
+	"fmt" // for printing
+)
+
+func main() {
+	// Print the name
+	fmt.Println(lib.Name)
+}
+`
+	expected := `// Copyright 2024 Acme Corp.
+
+//go:build linux && !cgo
+// +build linux,!cgo
+
+// Package main is an example.
+package main
+
+import (
+	"fmt" // for printing
+
+	"github.com/external/lib"
+)
+
+func main() {
+	// Print the name
+	fmt.Println(lib.Name)
+}
+`
+	testFile := filepath.Join(t.TempDir(), "main.go")
+	req.NoError(os.WriteFile(testFile, []byte(testGoContent), 0644))
+
+	g := New(FormatterConfig{
+		FilePath:       testFile,
+		CurrentProject: "github.com/test/project",
+		InPlace:        true,
+	})
+	req.NoError(g.ProcessFile())
+
+	processed, err := os.ReadFile(testFile)
+	req.NoError(err)
+	req.Equal(expected, string(processed))
+}
+
 func TestFormatter_formatImportSpec(t *testing.T) {
 	req := require.New(t)
 	g := New(FormatterConfig{
@@ -848,3 +927,174 @@ func TestFormatter_formatImportSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatter_ProcessFiles_Errors(t *testing.T) {
+	req := require.New(t)
+	tempDir := t.TempDir()
+
+	validFile := filepath.Join(tempDir, "valid.go")
+	req.NoError(os.WriteFile(validFile, []byte("package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println() }\n"), 0644))
+	brokenFile := filepath.Join(tempDir, "broken.go")
+	req.NoError(os.WriteFile(brokenFile, []byte("package main\n\nimport \"fmt\"\n\nfunc main() {\n"), 0644))
+	missingFile := filepath.Join(tempDir, "missing.go")
+
+	g := New(FormatterConfig{
+		CurrentProject: "github.com/test/project",
+		InPlace:        true,
+	})
+	err := g.ProcessFiles([]string{validFile, brokenFile, missingFile})
+	req.Error(err)
+
+	var multiErr *errors.MultiError
+	req.True(stderrors.As(err, &multiErr), "ProcessFiles should return a MultiError")
+	req.Len(multiErr.Errors, 2)
+
+	var parseErr *errors.ParseError
+	req.True(stderrors.As(err, &parseErr), "broken file should be reported as a ParseError")
+	req.Equal(brokenFile, parseErr.Path)
+	req.Equal(5, parseErr.Pos.Line)
+
+	var readErr *errors.ReadError
+	req.True(stderrors.As(err, &readErr), "missing file should be reported as a ReadError")
+	req.Equal(missingFile, readErr.Path)
+	req.ErrorIs(err, fs.ErrNotExist)
+}
+
+func TestFormatter_ProcessPath_JSONFormat(t *testing.T) {
+	req := require.New(t)
+	tempDir := t.TempDir()
+
+	req.NoError(os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte("module github.com/test/project\n\ngo 1.21\n"), 0644))
+	files := map[string]string{
+		"changed.go": "package main\n\nimport (\n\t\"github.com/myorg/lib\"\n\t\"github.com/test/project/internal\"\n\t\"fmt\"\n\t\"os\"\n)\n",
+		"grouped.go": "package main\n\nimport (\n\t\"fmt\"\n)\n",
+		"broken.go":  "package main\n\nimport (\n",
+	}
+	for name, content := range files {
+		req.NoError(os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644))
+	}
+
+	var buf bytes.Buffer
+	g := New(FormatterConfig{
+		Orgs:    []string{"github.com/myorg"},
+		InPlace: true,
+		Format:  report.FormatJSON,
+		Output:  &buf,
+	})
+	err := g.ProcessPath(tempDir)
+	req.Error(err, "broken file should fail the run")
+
+	var document struct {
+		Files   []report.FileRecord `json:"files"`
+		Summary report.Summary      `json:"summary"`
+	}
+	req.NoError(json.Unmarshal(buf.Bytes(), &document), "output should only contain the JSON document: %s", buf.String())
+	req.Equal(report.Summary{Total: 3, Changed: 1, Unchanged: 1, Errors: 1}, document.Summary)
+
+	records := make(map[string]report.FileRecord)
+	for _, record := range document.Files {
+		records[filepath.Base(record.Path)] = record
+	}
+
+	changed := records["changed.go"]
+	req.Equal(report.StatusChanged, changed.Status)
+	req.Equal("github.com/test/project", changed.Module)
+	req.Equal(map[string]int{"std": 2, "org:github.com/myorg": 1, "project": 1}, changed.Groups)
+
+	req.Equal(report.StatusUnchanged, records["grouped.go"].Status)
+
+	broken := records["broken.go"]
+	req.Equal(report.StatusError, broken.Status)
+	req.Equal(report.ErrorKindParse, broken.Error.Kind)
+	req.Equal(3, broken.Error.Line)
+}
+
+func TestFormatter_FormatImports(t *testing.T) {
+	req := require.New(t)
+	g := New(FormatterConfig{CurrentProject: "github.com/test/project"})
+
+	src := "package main\n\nimport (\n\t\"os\"\n\t\"fmt\"\n)\n\nfunc main() {\n\tfmt.Println(os.Args)\n}\n"
+	fix, err := g.FormatImports("main.go", []byte(src))
+	req.NoError(err)
+	req.Equal(&report.Fix{StartLine: 3, EndLine: 6, Replacement: "import (\n\t\"fmt\"\n\t\"os\"\n)"}, fix)
+
+	// Only the import block is considered, the rest of the file is not reformatted
+	src = "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() {  fmt.Println(os.Args) }\n"
+	fix, err = g.FormatImports("main.go", []byte(src))
+	req.NoError(err)
+	req.Nil(fix)
+
+	_, err = g.FormatImports("broken.go", []byte("package main\n\nimport (\n"))
+	req.Error(err)
+}
+
+func TestFormatter_ProcessFiles_BackupAndJournal(t *testing.T) {
+	req := require.New(t)
+	dir := t.TempDir()
+	journalDir := t.TempDir()
+	misgrouped := "package main\n\nimport (\n\t\"os\"\n\t\"fmt\"\n)\n\nfunc main() { fmt.Println(os.Args) }\n"
+	grouped := "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() { fmt.Println(os.Args) }\n"
+	misgroupedPath := filepath.Join(dir, "a.go")
+	groupedPath := filepath.Join(dir, "b.go")
+	req.NoError(os.WriteFile(misgroupedPath, []byte(misgrouped), 0644))
+	req.NoError(os.WriteFile(groupedPath, []byte(grouped), 0644))
+
+	recorder := journal.NewRecorder(journalDir)
+	g := New(FormatterConfig{
+		InPlace:        true,
+		CurrentProject: "github.com/test/project",
+		BackupSuffix:   ".orig",
+		Journal:        recorder,
+		Output:         &bytes.Buffer{},
+	})
+	req.NoError(g.ProcessFiles([]string{misgroupedPath, groupedPath}))
+	req.NoError(recorder.Close())
+
+	backup, err := os.ReadFile(misgroupedPath + ".orig")
+	req.NoError(err)
+	req.Equal(misgrouped, string(backup))
+	req.NoFileExists(groupedPath+".orig", "unchanged files are not backed up")
+
+	runs, err := journal.List(journalDir)
+	req.NoError(err)
+	req.Len(runs, 1)
+	req.Len(runs[0].Entries, 1)
+	req.Equal(misgroupedPath, runs[0].Entries[0].Path)
+
+	_, err = journal.Undo(journalDir, runs[0])
+	req.NoError(err)
+	content, err := os.ReadFile(misgroupedPath)
+	req.NoError(err)
+	req.Equal(misgrouped, string(content))
+	req.NoFileExists(misgroupedPath + ".orig")
+}
+
+func TestFormatter_ProcessFiles_JournalFailure(t *testing.T) {
+	req := require.New(t)
+	dir := t.TempDir()
+	misgrouped := "package main\n\nimport (\n\t\"os\"\n\t\"fmt\"\n)\n\nfunc main() { fmt.Println(os.Args) }\n"
+	path := filepath.Join(dir, "a.go")
+	req.NoError(os.WriteFile(path, []byte(misgrouped), 0644))
+	req.NoError(os.WriteFile(path+".orig", []byte("package previous\n"), 0644))
+
+	// The journal cannot be created under a regular file
+	blocker := filepath.Join(dir, "journal")
+	req.NoError(os.WriteFile(blocker, nil, 0644))
+	var output bytes.Buffer
+	g := New(FormatterConfig{
+		InPlace:        true,
+		CurrentProject: "github.com/test/project",
+		BackupSuffix:   ".orig",
+		Journal:        journal.NewRecorder(blocker),
+		Output:         &output,
+	})
+	req.NoError(g.ProcessFiles([]string{path}))
+	req.Contains(output.String(), "the run is not journaled")
+
+	content, err := os.ReadFile(path)
+	req.NoError(err)
+	req.Equal("package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() { fmt.Println(os.Args) }\n", string(content))
+	backup, err := os.ReadFile(path + ".orig")
+	req.NoError(err)
+	req.Equal("package previous\n", string(backup), "the backup of an earlier run is kept")
+}
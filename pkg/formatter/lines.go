@@ -0,0 +1,94 @@
+package formatter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+)
+
+// LineRange represents an inclusive range of 1-based line numbers
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// ParseLineRange parses a line range in the form "start:end"
+func ParseLineRange(value string) (LineRange, error) {
+	invalid := &errors.ConfigError{
+		Key: "lines",
+		Err: fmt.Errorf("%s: %q", errors.ErrMsgInvalidLineRange, value),
+	}
+
+	startStr, endStr, found := strings.Cut(value, ":")
+	if !found {
+		return LineRange{}, invalid
+	}
+
+	start, err := strconv.Atoi(strings.TrimSpace(startStr))
+	if err != nil {
+		return LineRange{}, invalid
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(endStr))
+	if err != nil {
+		return LineRange{}, invalid
+	}
+
+	if start < 1 || end < start {
+		return LineRange{}, invalid
+	}
+	return LineRange{Start: start, End: end}, nil
+}
+
+// Intersects checks if the range overlaps with the lines from start to end
+func (r LineRange) Intersects(start, end int) bool {
+	return r.Start <= end && start <= r.End
+}
+
+// String returns the range in the form "start:end"
+func (r LineRange) String() string {
+	return fmt.Sprintf("%d:%d", r.Start, r.End)
+}
+
+// importDeclLines returns the first and last line of the import declarations in a file
+func importDeclLines(fileSet *token.FileSet, file *ast.File) (int, int, bool) {
+	start, end := 0, 0
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+
+		declStart := fileSet.Position(genDecl.Pos()).Line
+		declEnd := fileSet.Position(genDecl.End()).Line
+		if start == 0 || declStart < start {
+			start = declStart
+		}
+		if declEnd > end {
+			end = declEnd
+		}
+	}
+	return start, end, start != 0
+}
+
+// intersectsLineRanges checks if the import declarations of a file overlap with
+// any of the configured line ranges. Files always match when no range is configured.
+func (g *formatter) intersectsLineRanges(file *ast.File) bool {
+	if len(g.config.LineRanges) == 0 {
+		return true
+	}
+
+	start, end, ok := importDeclLines(g.fileSet, file)
+	if !ok {
+		return false
+	}
+	for _, r := range g.config.LineRanges {
+		if r.Intersects(start, end) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package formatter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+func TestParseLineRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		expected  LineRange
+		expectErr bool
+	}{
+		{"valid range", "3:10", LineRange{Start: 3, End: 10}, false},
+		{"single line", "5:5", LineRange{Start: 5, End: 5}, false},
+		{"spaces around numbers", " 1 : 2 ", LineRange{Start: 1, End: 2}, false},
+		{"missing separator", "10", LineRange{}, true},
+		{"end before start", "10:3", LineRange{}, true},
+		{"zero start", "0:3", LineRange{}, true},
+		{"non-numeric", "a:b", LineRange{}, true},
+		{"empty end", "3:", LineRange{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			result, err := ParseLineRange(tt.value)
+			if tt.expectErr {
+				req.Error(err, "ParseLineRange(%q) expected error", tt.value)
+				return
+			}
+			req.NoError(err, "ParseLineRange(%q)", tt.value)
+			req.Equal(tt.expected, result, "ParseLineRange(%q)", tt.value)
+		})
+	}
+}
+
+func TestLineRange_Intersects(t *testing.T) {
+	req := require.New(t)
+	r := LineRange{Start: 5, End: 10}
+
+	req.True(r.Intersects(1, 5), "range touching start")
+	req.True(r.Intersects(10, 12), "range touching end")
+	req.True(r.Intersects(6, 7), "range inside")
+	req.True(r.Intersects(1, 20), "range around")
+	req.False(r.Intersects(1, 4), "range before")
+	req.False(r.Intersects(11, 20), "range after")
+}
+
+func TestFormatter_ProcessFile_LineRanges(t *testing.T) {
+	testGoContent := `package main
+
+import (
+	"github.com/external/lib"
+	"fmt"
+)
+
+func main() {
+	fmt.Println(lib.Name)
+}
+`
+
+	tests := []struct {
+		name        string
+		lineRanges  []LineRange
+		wantChanged bool
+	}{
+		{"no line ranges", nil, true},
+		{"range overlapping imports", []LineRange{{Start: 4, End: 4}}, true},
+		{"range before imports", []LineRange{{Start: 1, End: 2}}, false},
+		{"range in function body", []LineRange{{Start: 9, End: 9}}, false},
+		{"one of several ranges overlapping", []LineRange{{Start: 9, End: 9}, {Start: 6, End: 8}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			testFile := filepath.Join(t.TempDir(), "main.go")
+			req.NoError(os.WriteFile(testFile, []byte(testGoContent), 0644))
+
+			g := New(FormatterConfig{
+				FilePath:       testFile,
+				CurrentProject: "github.com/test/project",
+				InPlace:        true,
+				LineRanges:     tt.lineRanges,
+			})
+			result, err := g.processFile(false)
+			req.NoError(err)
+
+			processed, err := os.ReadFile(testFile)
+			req.NoError(err)
+			if tt.wantChanged {
+				req.Equal(report.StatusChanged, result.status)
+				req.NotEqual(testGoContent, string(processed), "file should be rewritten")
+			} else {
+				req.Equal(report.StatusSkipped, result.status)
+				req.Equal(testGoContent, string(processed), "file should be untouched")
+			}
+		})
+	}
+}
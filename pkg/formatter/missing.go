@@ -0,0 +1,221 @@
+package formatter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/resolver"
+)
+
+// missingImport is a qualifier used by a file that none of its imports provides
+type missingImport struct {
+	name       string
+	pos        token.Position // first use of the qualifier
+	candidates []string       // import paths of the packages that may provide it, sorted
+}
+
+// packageScope holds the top-level declarations and the imports of the other files of a package
+type packageScope struct {
+	decls   map[string]bool
+	imports map[string][]string // import paths by package name
+}
+
+// findMissingImports resolves the qualifiers of a file that none of its imports provides.
+// Qualifiers declared or imported by the other files of the package take precedence over
+// the packages found on disk.
+func (g *formatter) findMissingImports(file *ast.File) []missingImport {
+	type use struct {
+		symbols map[string]bool
+		pos     token.Pos
+	}
+	uses := make(map[string]*use)
+	ast.Inspect(file, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := selector.X.(*ast.Ident)
+		if !ok || ident.Obj != nil || !isPackageQualifier(ident.Name) {
+			return true
+		}
+		if uses[ident.Name] == nil {
+			uses[ident.Name] = &use{symbols: make(map[string]bool), pos: ident.Pos()}
+		}
+		uses[ident.Name].symbols[selector.Sel.Name] = true
+		return true
+	})
+	if len(uses) == 0 {
+		return nil
+	}
+
+	filePath := g.getFilePath()
+	for _, spec := range file.Imports {
+		importPath := strings.Trim(spec.Path.Value, `"`)
+		if spec.Name != nil {
+			delete(uses, spec.Name.Name)
+			continue
+		}
+		delete(uses, g.getResolver().PackageName(filePath, importPath))
+	}
+
+	scope := g.packageScope(file)
+	var missing []missingImport
+	for name, use := range uses {
+		if scope.decls[name] {
+			continue
+		}
+		symbols := make([]string, 0, len(use.symbols))
+		for symbol := range use.symbols {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+
+		var candidates []string
+		for _, importPath := range scope.imports[name] {
+			if g.getResolver().Exports(filePath, importPath, symbols) {
+				candidates = append(candidates, importPath)
+			}
+		}
+		if len(candidates) != 1 {
+			candidates = g.getResolver().Candidates(filePath, name, symbols)
+		}
+		missing = append(missing, missingImport{name: name, pos: g.fileSet.Position(use.pos), candidates: candidates})
+	}
+
+	sort.Slice(missing, func(i, j int) bool {
+		return missing[i].pos.Offset < missing[j].pos.Offset
+	})
+	return missing
+}
+
+// addMissing adds the imports of the missing qualifiers that resolve to a single package
+func addMissing(imports []Import, missing []missingImport) []Import {
+	for _, m := range missing {
+		if len(m.candidates) == 1 {
+			imports = append(imports, Import{Path: m.candidates[0]})
+		}
+	}
+	return imports
+}
+
+// missingImportMessage describes a missing import along with the packages that may provide it
+func missingImportMessage(m missingImport) string {
+	if len(m.candidates) == 1 {
+		return fmt.Sprintf("%s is not imported, expected %q", m.name, m.candidates[0])
+	}
+	quoted := make([]string, len(m.candidates))
+	for i, candidate := range m.candidates {
+		quoted[i] = strconv.Quote(candidate)
+	}
+	return fmt.Sprintf("%s is not imported and matches several packages: %s", m.name, strings.Join(quoted, ", "))
+}
+
+// ambiguousImportViolation reports a missing import that was not added since several packages match
+func ambiguousImportViolation(m missingImport) report.Violation {
+	return report.Violation{
+		Code:    report.RuleCode(report.RuleMissingImport),
+		Rule:    report.RuleMissingImport,
+		Message: missingImportMessage(m),
+		Line:    m.pos.Line,
+		Column:  m.pos.Column,
+	}
+}
+
+// packageScope collects the declarations and imports of the other files of the package
+// of a file. Test files see the declarations of all the files of their package.
+func (g *formatter) packageScope(file *ast.File) *packageScope {
+	filePath, err := filepath.Abs(g.getFilePath())
+	if err != nil {
+		filePath = g.getFilePath()
+	}
+	isTest := strings.HasSuffix(filePath, "_test.go")
+	key := filepath.Dir(filePath) + "\x00" + file.Name.Name + "\x00" + strconv.FormatBool(isTest)
+	if scope, ok := g.scopes[key]; ok {
+		return scope
+	}
+
+	scope := &packageScope{decls: make(map[string]bool), imports: make(map[string][]string)}
+	if g.scopes == nil {
+		g.scopes = make(map[string]*packageScope)
+	}
+	g.scopes[key] = scope
+
+	entries, err := os.ReadDir(filepath.Dir(filePath))
+	if err != nil {
+		return scope
+	}
+	fileSet := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		siblingPath := filepath.Join(filepath.Dir(filePath), name)
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || siblingPath == filePath {
+			continue
+		}
+		if strings.HasSuffix(name, "_test.go") && !isTest {
+			continue
+		}
+		sibling, err := parser.ParseFile(fileSet, siblingPath, nil, parser.SkipObjectResolution)
+		if err != nil || sibling.Name.Name != file.Name.Name {
+			continue
+		}
+
+		for _, decl := range sibling.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					scope.decls[decl.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						scope.decls[spec.Name.Name] = true
+					case *ast.ValueSpec:
+						for _, ident := range spec.Names {
+							scope.decls[ident.Name] = true
+						}
+					case *ast.ImportSpec:
+						importPath := strings.Trim(spec.Path.Value, `"`)
+						name := g.getResolver().PackageName(siblingPath, importPath)
+						if spec.Name != nil {
+							name = spec.Name.Name
+						}
+						if name == "_" || name == "." || importPath == "C" {
+							continue
+						}
+						if !slices.Contains(scope.imports[name], importPath) {
+							scope.imports[name] = append(scope.imports[name], importPath)
+						}
+					}
+				}
+			}
+		}
+	}
+	return scope
+}
+
+// getResolver returns the resolver of the formatter, created on first use
+func (g *formatter) getResolver() *resolver.Resolver {
+	if g.config.Resolver == nil {
+		g.config.Resolver = resolver.New()
+	}
+	return g.config.Resolver
+}
+
+// isPackageQualifier checks if an identifier may name a package. Package names are
+// lower case by convention, upper case qualifiers are method expressions of dot imports.
+func isPackageQualifier(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return name != "_" && !unicode.IsUpper(r)
+}
@@ -0,0 +1,163 @@
+package formatter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+func TestFormatter_AddMissing(t *testing.T) {
+	tests := []struct {
+		name     string
+		siblings map[string]string
+		src      string
+		expected string
+		warnings []string
+	}{
+		{
+			name: "standard library qualifiers",
+			src: `package main
+
+import "fmt"
+
+func main() { fmt.Println(json.Marshal(nil)) }
+`,
+			expected: `package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+func main() { fmt.Println(json.Marshal(nil)) }
+`,
+		},
+		{
+			name: "file without imports",
+			src: `package main
+
+func main() { _ = strings.ToUpper("") }
+`,
+			expected: `package main
+
+import (
+	"strings"
+)
+
+func main() { _ = strings.ToUpper("") }
+`,
+		},
+		{
+			name: "ambiguous qualifiers are reported",
+			src: `package main
+
+import "fmt"
+
+func main() { fmt.Println(rand.Int()) }
+`,
+			expected: `package main
+
+import (
+	"fmt"
+)
+
+func main() { fmt.Println(rand.Int()) }
+`,
+			warnings: []string{`rand is not imported and matches several packages: "crypto/rand", "math/rand", "math/rand/v2"`},
+		},
+		{
+			name:     "declarations and imports of the package",
+			siblings: map[string]string{"other.go": "package main\n\nimport \"math/rand/v2\"\n\nvar config struct{ Name string }\n"},
+			src: `package main
+
+import "fmt"
+
+func main() { fmt.Println(config.Name, rand.Int()) }
+`,
+			expected: `package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+func main() { fmt.Println(config.Name, rand.Int()) }
+`,
+		},
+		{
+			name: "unknown and shadowed qualifiers",
+			src: `package main
+
+import "fmt"
+
+func main() {
+	strings := struct{ Len int }{}
+	fmt.Println(strings.Len, unknown.Value)
+}
+`,
+			expected: `package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	strings := struct{ Len int }{}
+	fmt.Println(strings.Len, unknown.Value)
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			dir := t.TempDir()
+			for name, content := range tt.siblings {
+				req.NoError(os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+			}
+
+			g := New(FormatterConfig{FilePath: filepath.Join(dir, "main.go"), CurrentProject: "github.com/test/project", AddMissing: true})
+			result, err := g.formatSource([]byte(tt.src))
+			req.NoError(err)
+			req.Equal(tt.expected, string(result.output))
+
+			var warnings []string
+			for _, warning := range result.warnings {
+				req.Equal(report.RuleMissingImport, warning.Rule)
+				warnings = append(warnings, warning.Message)
+			}
+			req.Equal(tt.warnings, warnings)
+		})
+	}
+}
+
+func TestFormatter_FindViolations_Missing(t *testing.T) {
+	req := require.New(t)
+	src := "package main\n\nimport (\n\t\"fmt\"\n)\n\nfunc main() { fmt.Println(json.Valid(nil), rand.Int()) }\n"
+
+	g := New(FormatterConfig{FilePath: filepath.Join(t.TempDir(), "main.go"), CurrentProject: "github.com/test/project", Check: true, AddMissing: true})
+	result, err := g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Equal(report.StatusChanged, result.status)
+	req.Equal([]report.Violation{
+		{
+			Code:    "GIG008",
+			Rule:    report.RuleMissingImport,
+			Message: `json is not imported, expected "encoding/json"`,
+			Line:    7,
+			Column:  27,
+		},
+		{
+			Code:    "GIG008",
+			Rule:    report.RuleMissingImport,
+			Message: `rand is not imported and matches several packages: "crypto/rand", "math/rand", "math/rand/v2"`,
+			Line:    7,
+			Column:  44,
+		},
+	}, result.violations)
+}
@@ -0,0 +1,150 @@
+package formatter
+
+import (
+	"fmt"
+	"go/ast"
+	"path/filepath"
+
+	"golang.org/x/tools/go/ast/astutil"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/config"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+// importPolicyViolation checks a dot or blank import against the policies of the
+// configuration. It returns the rule and message of the violation, empty when allowed.
+func (g *formatter) importPolicyViolation(file *ast.File, imp Import) (string, string) {
+	var policy config.ImportPolicy
+	var rule, kind string
+	switch imp.Name {
+	case ".":
+		policy, rule, kind = g.config.DotImports, report.RuleDotImport, "dot"
+	case "_":
+		policy, rule, kind = g.config.BlankImports, report.RuleBlankImport, "blank"
+	default:
+		return "", ""
+	}
+
+	filePath, err := filepath.Abs(g.getFilePath())
+	if err != nil {
+		filePath = g.getFilePath()
+	}
+	if policy.Allows(imp.Path, g.isStdImport(imp.Path), filePath, file.Name.Name) {
+		return "", ""
+	}
+
+	message := fmt.Sprintf("%s import of %q is not allowed", kind, imp.Path)
+	switch policy.Policy {
+	case config.PolicyAllowInTests:
+		message += " outside of tests"
+	case config.PolicyAllowInMain:
+		message += " outside of main packages"
+	}
+	return rule, message
+}
+
+// applyImportPolicies converts the dot imports that are not allowed into named imports,
+// qualifying the identifiers of the file that refer to them. It returns the number of
+// converted imports. Dot imports that cannot be converted safely and blank imports that
+// are not allowed are reported as warnings.
+func (g *formatter) applyImportPolicies(file *ast.File, imports []Import) (int, []report.Violation) {
+	converted := 0
+	var warnings []report.Violation
+	for i := range imports {
+		rule, message := g.importPolicyViolation(file, imports[i])
+		if rule == "" {
+			continue
+		}
+		if rule == report.RuleDotImport {
+			if name := g.packageName(imports[i].Path); g.qualifyDotImport(file, imports, i, name) {
+				imports[i].Name = ""
+				converted++
+				continue
+			}
+			message += ", it cannot be converted safely"
+		}
+		warnings = append(warnings, g.importViolation(file, imports[i], rule, "%s", message))
+	}
+	return converted, warnings
+}
+
+// packageName returns the name of the package of an import path
+func (g *formatter) packageName(importPath string) string {
+	return g.importName(Import{Path: importPath})
+}
+
+// qualifyDotImport qualifies the identifiers referring to the exported names of the
+// index-th import, a dot import, with the name of its package. Without type information
+// it only does so when the names of the package are known and every identifier can be
+// attributed to it, and reports whether it did.
+func (g *formatter) qualifyDotImport(file *ast.File, imports []Import, index int, name string) bool {
+	filePath := g.getFilePath()
+	exports := g.getResolver().ExportedNames(filePath, imports[index].Path)
+	if exports == nil || g.isNameTaken(file, imports, name) {
+		return false
+	}
+	var others []map[string]bool // exported names of the other dot imports
+	for i, imp := range imports {
+		if i == index || imp.Name != "." {
+			continue
+		}
+		otherExports := g.getResolver().ExportedNames(filePath, imp.Path)
+		if otherExports == nil {
+			return false
+		}
+		others = append(others, otherExports)
+	}
+
+	scope := g.packageScope(file)
+	idents := make(map[*ast.Ident]bool)
+	safe := true
+	astutil.Apply(file, func(c *astutil.Cursor) bool {
+		ident, ok := c.Node().(*ast.Ident)
+		if !ok || ident.Obj != nil || !exports[ident.Name] || scope.decls[ident.Name] {
+			return true
+		}
+		switch c.Parent().(type) {
+		case *ast.SelectorExpr:
+			if c.Name() == "Sel" {
+				return true
+			}
+		case *ast.KeyValueExpr:
+			if c.Name() == "Key" {
+				safe = false // Either a field name or a constant of the package
+				return false
+			}
+		case *ast.Field:
+			if c.Name() == "Names" {
+				return true
+			}
+		case *ast.FuncDecl, *ast.File, *ast.ImportSpec:
+			if c.Name() == "Name" {
+				return true
+			}
+		case *ast.LabeledStmt, *ast.BranchStmt:
+			if c.Name() == "Label" {
+				return true
+			}
+		}
+		for _, otherExports := range others {
+			if otherExports[ident.Name] {
+				safe = false
+				return false
+			}
+		}
+		idents[ident] = true
+		return true
+	}, nil)
+	if !safe {
+		return false
+	}
+
+	astutil.Apply(file, func(c *astutil.Cursor) bool {
+		if ident, ok := c.Node().(*ast.Ident); ok && idents[ident] {
+			c.Replace(&ast.SelectorExpr{X: &ast.Ident{Name: name, NamePos: ident.Pos()}, Sel: ident})
+			return false
+		}
+		return true
+	}, nil)
+	return true
+}
@@ -0,0 +1,276 @@
+package formatter
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/config"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+// newPolicyModule creates a module with packages meant to be dot imported
+func newPolicyModule(t *testing.T) string {
+	t.Helper()
+	return writeModule(t, map[string]string{
+		"go.mod":           "module example.com/app\n\ngo 1.22\n",
+		"color/color.go":   "package color\n\ntype Color int\n\nconst Red Color = 1\n\nfunc Mix(a, b Color) Color { return a + b }\n",
+		"shade/shade.go":   "package shade\n\nfunc Mix() {}\n",
+		"matcher/match.go": "package matcher\n\nfunc Equal() {}\n",
+	})
+}
+
+func TestFormatter_ImportPolicies(t *testing.T) {
+	forbid := config.ImportPolicy{Policy: config.PolicyForbid}
+	tests := []struct {
+		name     string
+		fileName string
+		dot      config.ImportPolicy
+		blank    config.ImportPolicy
+		src      string
+		expected string
+		warnings []string
+	}{
+		{
+			name: "dot import converted",
+			dot:  forbid,
+			src: `package main
+
+import (
+	"fmt"
+
+	. "example.com/app/color"
+)
+
+func main() {
+	var c Color = Mix(Red, 2)
+	Red := 3
+	fmt.Println(c, Red)
+}
+`,
+			expected: `package main
+
+import (
+	"fmt"
+
+	"example.com/app/color"
+)
+
+func main() {
+	var c color.Color = color.Mix(color.Red, 2)
+	Red := 3
+	fmt.Println(c, Red)
+}
+`,
+		},
+		{
+			name:     "dot import allowed in tests",
+			fileName: "main_test.go",
+			dot:      config.ImportPolicy{Policy: config.PolicyAllowInTests},
+			src: `package main
+
+import (
+	. "example.com/app/color"
+)
+
+var _ = Red
+`,
+			expected: `package main
+
+import (
+	. "example.com/app/color"
+)
+
+var _ = Red
+`,
+		},
+		{
+			name: "dot import of an allowed path",
+			dot:  config.ImportPolicy{Policy: config.PolicyForbid, Allow: []string{"example.com/app/matcher"}},
+			src: `package main
+
+import (
+	. "example.com/app/color"
+	. "example.com/app/matcher"
+)
+
+var _, _ = Red, Equal
+`,
+			expected: `package main
+
+import (
+	"example.com/app/color"
+	. "example.com/app/matcher"
+)
+
+var _, _ = color.Red, Equal
+`,
+		},
+		{
+			name: "names exported by several dot imports",
+			dot:  forbid,
+			src: `package main
+
+import (
+	. "example.com/app/color"
+	. "example.com/app/shade"
+)
+
+var _ = Mix
+`,
+			expected: `package main
+
+import (
+	. "example.com/app/color"
+	. "example.com/app/shade"
+)
+
+var _ = Mix
+`,
+			warnings: []string{
+				`dot import of "example.com/app/color" is not allowed, it cannot be converted safely`,
+				`dot import of "example.com/app/shade" is not allowed, it cannot be converted safely`,
+			},
+		},
+		{
+			name: "package name taken",
+			dot:  forbid,
+			src: `package main
+
+import (
+	. "example.com/app/color"
+)
+
+var color = Red
+`,
+			expected: `package main
+
+import (
+	. "example.com/app/color"
+)
+
+var color = Red
+`,
+			warnings: []string{`dot import of "example.com/app/color" is not allowed, it cannot be converted safely`},
+		},
+		{
+			name:  "blank import",
+			blank: config.ImportPolicy{Policy: config.PolicyAllowInMain},
+			src: `package lib
+
+import (
+	_ "embed"
+)
+`,
+			expected: `package lib
+
+import (
+	_ "embed"
+)
+`,
+			warnings: []string{`blank import of "embed" is not allowed outside of main packages`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			root := newPolicyModule(t)
+			fileName := tt.fileName
+			if fileName == "" {
+				fileName = "main.go"
+			}
+
+			g := New(FormatterConfig{
+				FilePath:       filepath.Join(root, fileName),
+				CurrentProject: "example.com/app",
+				DotImports:     tt.dot,
+				BlankImports:   tt.blank,
+			})
+			result, err := g.formatSource([]byte(tt.src))
+			req.NoError(err)
+			req.Equal(tt.expected, string(result.output))
+
+			var warnings []string
+			for _, warning := range result.warnings {
+				warnings = append(warnings, warning.Message)
+			}
+			req.Equal(tt.warnings, warnings)
+		})
+	}
+}
+
+func TestFormatter_ImportPolicies_DotImportedTypes(t *testing.T) {
+	req := require.New(t)
+	root := newPolicyModule(t)
+	src := `package main
+
+import . "time"
+
+type S struct{ D Duration }
+
+func F(d Duration) Duration { return d * Second }
+
+func main() { _ = S{D: F(Minute)} }
+`
+	expected := `package main
+
+import (
+	"time"
+)
+
+type S struct{ D time.Duration }
+
+func F(d time.Duration) time.Duration { return d * time.Second }
+
+func main() { _ = S{D: F(time.Minute)} }
+`
+
+	g := New(FormatterConfig{
+		FilePath:       filepath.Join(root, "main.go"),
+		CurrentProject: "example.com/app",
+		DotImports:     config.ImportPolicy{Policy: config.PolicyForbid},
+	})
+	result, err := g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Equal(expected, string(result.output))
+
+	// The converted file compiles
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, "main.go", result.output, 0)
+	req.NoError(err)
+	conf := types.Config{Importer: importer.ForCompiler(fileSet, "source", nil)}
+	_, err = conf.Check("main", fileSet, []*ast.File{file}, nil)
+	req.NoError(err)
+}
+
+func TestFormatter_FindViolations_ImportPolicies(t *testing.T) {
+	req := require.New(t)
+	root := newPolicyModule(t)
+	src := "package main\n\nimport (\n\t_ \"embed\"\n\n\t. \"example.com/app/color\"\n)\n\nvar _ = Red\n"
+
+	g := New(FormatterConfig{
+		FilePath:       filepath.Join(root, "main.go"),
+		CurrentProject: "example.com/app",
+		Check:          true,
+		DotImports:     config.ImportPolicy{Policy: config.PolicyAllowInTests},
+		BlankImports:   config.ImportPolicy{Policy: config.PolicyAllowInMain},
+	})
+	result, err := g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Equal(report.StatusChanged, result.status)
+	req.Equal([]report.Violation{{
+		Code:    "GIG014",
+		Rule:    report.RuleDotImport,
+		Message: `dot import of "example.com/app/color" is not allowed outside of tests`,
+		Line:    6,
+		Column:  2,
+	}}, result.violations)
+	req.Nil(result.fix, "uses of the dot import are qualified outside of the import block")
+}
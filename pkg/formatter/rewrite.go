@@ -0,0 +1,106 @@
+package formatter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"strings"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+)
+
+// prefixSuffix marks the paths of prefix rewrites, which also apply to subpackages
+const prefixSuffix = "/..."
+
+// Rewrite replaces an import path by another one, e.g. when a module moves. Prefix
+// rewrites, whose paths end with /..., also replace the paths of the subpackages.
+type Rewrite struct {
+	From string
+	To   string
+}
+
+// ParseRewrite validates a rewrite: both paths must be prefixes, or neither
+func ParseRewrite(from, to string) (Rewrite, error) {
+	if err := validateRewrite(from, to); err != nil {
+		return Rewrite{}, &errors.ConfigError{Key: "from", Err: err}
+	}
+	return Rewrite{From: from, To: to}, nil
+}
+
+// validateRewrite checks the paths of a rewrite
+func validateRewrite(from, to string) error {
+	if from == "" || to == "" || strings.HasSuffix(from, prefixSuffix) != strings.HasSuffix(to, prefixSuffix) {
+		return fmt.Errorf("%s: %q => %q", errors.ErrMsgInvalidRewrite, from, to)
+	}
+	return nil
+}
+
+// ReadRewrites reads a mapping file holding one rewrite per line, its two paths separated
+// by spaces. Blank lines and lines starting with # are ignored.
+func ReadRewrites(path string) ([]Rewrite, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &errors.ConfigError{Path: path, Err: err}
+	}
+
+	var rewrites []Rewrite
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		pos := token.Position{Filename: path, Line: line, Column: 1}
+		if len(fields) != 2 {
+			return nil, &errors.ConfigError{Path: path, Pos: pos, Err: fmt.Errorf("%s: %q", errors.ErrMsgInvalidMapping, text)}
+		}
+		if err := validateRewrite(fields[0], fields[1]); err != nil {
+			return nil, &errors.ConfigError{Path: path, Pos: pos, Err: err}
+		}
+		rewrites = append(rewrites, Rewrite{From: fields[0], To: fields[1]})
+	}
+	return rewrites, nil
+}
+
+// Apply returns the path an import path is rewritten to, false when the rewrite does not apply
+func (r Rewrite) Apply(importPath string) (string, bool) {
+	from, ok := strings.CutSuffix(r.From, prefixSuffix)
+	if !ok {
+		if importPath != r.From {
+			return "", false
+		}
+		return r.To, true
+	}
+	to := strings.TrimSuffix(r.To, prefixSuffix)
+	if importPath == from {
+		return to, true
+	}
+	if rest, ok := strings.CutPrefix(importPath, from+"/"); ok {
+		return to + "/" + rest, true
+	}
+	return "", false
+}
+
+// rewrittenPath applies the first matching rewrite to an import path, false when none matches
+func (g *formatter) rewrittenPath(importPath string) (string, bool) {
+	for _, rewrite := range g.config.Rewrites {
+		if newPath, ok := rewrite.Apply(importPath); ok {
+			return newPath, true
+		}
+	}
+	return "", false
+}
+
+// hasRewrites checks if any import of a file is rewritten
+func (g *formatter) hasRewrites(file *ast.File) bool {
+	for _, spec := range file.Imports {
+		if _, ok := g.rewrittenPath(strings.Trim(spec.Path.Value, `"`)); ok {
+			return true
+		}
+	}
+	return false
+}
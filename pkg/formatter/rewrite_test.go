@@ -0,0 +1,127 @@
+package formatter
+
+import (
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+func TestRewrite_Apply(t *testing.T) {
+	tests := []struct {
+		rewrite    Rewrite
+		importPath string
+		expected   string
+		applies    bool
+	}{
+		{Rewrite{"github.com/old-org/lib", "github.com/acme/lib/v2"}, "github.com/old-org/lib", "github.com/acme/lib/v2", true},
+		{Rewrite{"github.com/old-org/lib", "github.com/acme/lib/v2"}, "github.com/old-org/lib/client", "", false},
+		{Rewrite{"github.com/old-org/lib/...", "github.com/acme/lib/v2/..."}, "github.com/old-org/lib", "github.com/acme/lib/v2", true},
+		{Rewrite{"github.com/old-org/lib/...", "github.com/acme/lib/v2/..."}, "github.com/old-org/lib/client", "github.com/acme/lib/v2/client", true},
+		{Rewrite{"github.com/old-org/lib/...", "github.com/acme/lib/v2/..."}, "github.com/old-org/library", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rewrite.From+" "+tt.importPath, func(t *testing.T) {
+			req := require.New(t)
+			newPath, ok := tt.rewrite.Apply(tt.importPath)
+			req.Equal(tt.applies, ok)
+			req.Equal(tt.expected, newPath)
+		})
+	}
+}
+
+func TestReadRewrites(t *testing.T) {
+	req := require.New(t)
+	path := filepath.Join(t.TempDir(), "rewrites.txt")
+	req.NoError(os.WriteFile(path, []byte("# Moved modules\ngithub.com/old-org/lib/...  github.com/acme/lib/v2/...\n\ngithub.com/old-org/trace\tgithub.com/acme/tracing\n"), 0644))
+
+	rewrites, err := ReadRewrites(path)
+	req.NoError(err)
+	req.Equal([]Rewrite{
+		{From: "github.com/old-org/lib/...", To: "github.com/acme/lib/v2/..."},
+		{From: "github.com/old-org/trace", To: "github.com/acme/tracing"},
+	}, rewrites)
+
+	req.NoError(os.WriteFile(path, []byte("github.com/old-org/lib/... github.com/acme/lib/v2/...\ngithub.com/old-org/trace/... github.com/acme/tracing\n"), 0644))
+	_, err = ReadRewrites(path)
+	var configErr *errors.ConfigError
+	req.True(stderrors.As(err, &configErr))
+	req.Equal(2, configErr.Pos.Line)
+
+	_, err = ParseRewrite("github.com/old-org/lib", "")
+	req.Error(err)
+}
+
+func TestFormatter_Rewrites(t *testing.T) {
+	req := require.New(t)
+	rewrites := []Rewrite{
+		{From: "github.com/old-org/lib/...", To: "github.com/test/project/lib/..."},
+		{From: "github.com/old-org/go-trace", To: "github.com/acme/tracing"},
+	}
+	src := `package main
+
+import (
+	"fmt"
+
+	"github.com/old-org/go-trace"
+	"github.com/old-org/lib/client"
+)
+
+func main() { fmt.Println(trace.Start, client.New) }
+`
+	expected := `package main
+
+import (
+	"fmt"
+
+	trace "github.com/acme/tracing"
+
+	"github.com/test/project/lib/client"
+)
+
+func main() { fmt.Println(trace.Start, client.New) }
+`
+	g := New(FormatterConfig{FilePath: filepath.Join(t.TempDir(), "main.go"), CurrentProject: "github.com/test/project", Rewrites: rewrites})
+	result, err := g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Equal(expected, string(result.output))
+
+	// Files not importing the rewritten paths are left as is
+	src = "package main\n\nimport (\n\t\"os\"\n\t\"fmt\"\n)\n"
+	result, err = g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Equal(report.StatusUnchanged, result.status)
+	req.Equal(src, string(result.output))
+}
+
+func TestFormatter_Rewrites_AlreadyImported(t *testing.T) {
+	req := require.New(t)
+	rewrites := []Rewrite{{From: "github.com/old/lib", To: "github.com/new/lib"}}
+	src := `package main
+
+import (
+	"github.com/old/lib"
+	lib2 "github.com/new/lib"
+)
+
+func main() { lib.A(); lib2.B() }
+`
+	expected := `package main
+
+import (
+	lib2 "github.com/new/lib"
+)
+
+func main() { lib2.A(); lib2.B() }
+`
+	g := New(FormatterConfig{FilePath: filepath.Join(t.TempDir(), "main.go"), CurrentProject: "github.com/test/project", Rewrites: rewrites})
+	result, err := g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Equal(expected, string(result.output))
+}
@@ -0,0 +1,89 @@
+package formatter
+
+import (
+	"fmt"
+	"go/ast"
+	"path/filepath"
+	"strings"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/config"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+// importDenial explains why the configured rules do not allow an import in a file
+type importDenial struct {
+	message     string
+	replacement string // import path suggested instead, empty for none
+}
+
+// deniedImport checks an import path against the rules applying to the current file,
+// nil when it is allowed. Rules are evaluated in order, the first denial applies.
+func (g *formatter) deniedImport(importPath string) *importDenial {
+	if len(g.config.Rules) == 0 || importPath == "C" {
+		return nil
+	}
+	filePath, err := filepath.Abs(g.getFilePath())
+	if err != nil {
+		filePath = g.getFilePath()
+	}
+	isStd := g.isStdImport(importPath)
+
+	for _, rule := range g.config.Rules {
+		if !rule.MatchFile(filePath) {
+			continue
+		}
+		for _, deny := range rule.Deny {
+			if !config.MatchImport(deny.Path, importPath, isStd) {
+				continue
+			}
+			message := fmt.Sprintf("%q is denied", importPath)
+			if deny.Message != "" {
+				message += ": " + deny.Message
+			}
+			if deny.Replacement != "" {
+				message += fmt.Sprintf(", use %q instead", deny.Replacement)
+			}
+			return &importDenial{message: message, replacement: deny.Replacement}
+		}
+		if len(rule.Allow) > 0 && !matchesAny(rule.Allow, importPath, isStd) {
+			return &importDenial{message: fmt.Sprintf("%q is not in the allowed imports", importPath)}
+		}
+	}
+	return nil
+}
+
+// deniedImportWarnings reports the denied imports of a file that are not replaced
+func (g *formatter) deniedImportWarnings(file *ast.File) []report.Violation {
+	var warnings []report.Violation
+	for _, spec := range file.Imports {
+		importPath := strings.Trim(spec.Path.Value, `"`)
+		denial := g.deniedImport(importPath)
+		if denial == nil || (g.config.ReplaceDenied && denial.replacement != "") {
+			continue
+		}
+		warnings = append(warnings, g.importViolation(file, Import{Path: importPath}, report.RuleDeniedImport, "%s", denial.message))
+	}
+	return warnings
+}
+
+// rewriteImportPath changes the path of an import, aliasing it to its previous name when
+// the new package has another one so that the file keeps referring to it
+func (g *formatter) rewriteImportPath(imp Import, newPath string) Import {
+	if imp.Name == "" {
+		if name := g.importName(imp); name != g.importName(Import{Path: newPath}) {
+			imp.Name = name
+		}
+	}
+	imp.Path = newPath
+	return imp
+}
+
+// matchesAny checks if an import path matches any of the patterns
+func matchesAny(patterns []string, importPath string, isStd bool) bool {
+	for _, pattern := range patterns {
+		if config.MatchImport(pattern, importPath, isStd) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package formatter
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/config"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+// testRules denies legacy packages in production code and restricts the tests
+func testRules(root string) []config.Rule {
+	return []config.Rule{
+		{
+			Root:  root,
+			Files: []string{"!*_test.go"},
+			Deny: []config.Deny{
+				{Path: "github.com/pkg/errors", Message: "wrapping is supported by fmt.Errorf", Replacement: "errors"},
+				{Path: "io/ioutil", Replacement: "os"},
+				{Path: "log"},
+				{Path: "github.com/stretchr/testify/..."},
+			},
+		},
+		{
+			Root:  root,
+			Files: []string{"*_test.go"},
+			Allow: []string{config.StdPattern, "github.com/stretchr/testify/...", "github.com/test/project/..."},
+		},
+	}
+}
+
+func TestFormatter_FindViolations_Denied(t *testing.T) {
+	req := require.New(t)
+	root := t.TempDir()
+	src := `package main
+
+import (
+	"io/ioutil"
+	"log"
+	"log/slog"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+`
+	g := New(FormatterConfig{FilePath: filepath.Join(root, "main.go"), CurrentProject: "github.com/test/project", Check: true, Rules: testRules(root)})
+	result, err := g.formatSource([]byte(src))
+	req.NoError(err)
+
+	var messages []string
+	for _, violation := range result.violations {
+		req.Equal(report.RuleDeniedImport, violation.Rule)
+		messages = append(messages, violation.Message)
+	}
+	req.Equal([]string{
+		`"io/ioutil" is denied, use "os" instead`,
+		`"log" is denied`,
+		`"github.com/pkg/errors" is denied: wrapping is supported by fmt.Errorf, use "errors" instead`,
+		`"github.com/stretchr/testify/require" is denied`,
+	}, messages)
+	req.Equal(8, result.violations[2].Line)
+
+	// Tests only allow the standard library, testify and the project
+	src = "package main\n\nimport (\n\t\"log\"\n\n\t\"github.com/pkg/errors\"\n\t\"github.com/stretchr/testify/require\"\n)\n"
+	g = New(FormatterConfig{FilePath: filepath.Join(root, "main_test.go"), CurrentProject: "github.com/test/project", Check: true, Rules: testRules(root)})
+	result, err = g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Equal([]report.Violation{{
+		Code:    "GIG011",
+		Rule:    report.RuleDeniedImport,
+		Message: `"github.com/pkg/errors" is not in the allowed imports`,
+		Line:    6,
+		Column:  2,
+	}}, result.violations)
+}
+
+func TestFormatter_ReplaceDenied(t *testing.T) {
+	req := require.New(t)
+	root := t.TempDir()
+	src := `package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+
+	"github.com/pkg/errors"
+)
+
+func main() {
+	_, err := ioutil.ReadFile("a")
+	log.Println(errors.Is(err, nil))
+}
+`
+	expected := `package main
+
+import (
+	"errors"
+	"log"
+	ioutil "os"
+)
+
+func main() {
+	_, err := ioutil.ReadFile("a")
+	log.Println(errors.Is(err, nil))
+}
+`
+	g := New(FormatterConfig{FilePath: filepath.Join(root, "main.go"), CurrentProject: "github.com/test/project", Rules: testRules(root), ReplaceDenied: true})
+	result, err := g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Equal(expected, string(result.output))
+	req.Len(result.warnings, 1)
+	req.Equal(`"log" is denied`, result.warnings[0].Message)
+	req.Equal(6, result.warnings[0].Line)
+}
+
+func TestFormatter_ReplaceDenied_AlreadyImported(t *testing.T) {
+	req := require.New(t)
+	root := t.TempDir()
+	src := `package main
+
+import (
+	"io/ioutil"
+	"os"
+)
+
+func main() { _, _ = ioutil.ReadFile(os.Args[0]) }
+`
+	expected := `package main
+
+import (
+	"os"
+)
+
+func main() { _, _ = os.ReadFile(os.Args[0]) }
+`
+	g := New(FormatterConfig{FilePath: filepath.Join(root, "main.go"), CurrentProject: "github.com/test/project", Rules: testRules(root), ReplaceDenied: true})
+	result, err := g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Equal(expected, string(result.output))
+
+	// Uses are renamed outside of the import declaration, check mode has no fix
+	g = New(FormatterConfig{FilePath: filepath.Join(root, "main.go"), CurrentProject: "github.com/test/project", Rules: testRules(root), ReplaceDenied: true, Check: true})
+	result, err = g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Nil(result.fix)
+}
@@ -0,0 +1,78 @@
+package formatter
+
+import (
+	"strings"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/config"
+)
+
+// sortKeys returns the configured sort keys of a group: its own, those of every
+// organization group, or the default ones
+func (g *formatter) sortKeys(group ImportGroup) []string {
+	if keys, ok := g.config.Sort[g.groupName(group)]; ok {
+		return keys
+	}
+	if keys, ok := g.config.Sort[config.SortGroupOrg]; ok && group >= OrgGroupBase {
+		return keys
+	}
+	return g.config.Sort[config.SortGroupDefault]
+}
+
+// compareImports compares two imports by a sort key
+func (g *formatter) compareImports(key string, a, b Import) int {
+	switch key {
+	case config.SortNatural:
+		return compareNatural(a.Path, b.Path)
+	case config.SortCaseInsensitive:
+		return strings.Compare(strings.ToLower(a.Path), strings.ToLower(b.Path))
+	case config.SortAlias:
+		return strings.Compare(g.importName(a), g.importName(b))
+	case config.SortAliasedLast:
+		return compareBool(a.Name != "", b.Name != "")
+	}
+	return strings.Compare(a.Path, b.Path)
+}
+
+// compareNatural compares strings comparing their runs of digits as numbers, e.g. v2 < v10
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		digitsA, digitsB := leadingDigits(a), leadingDigits(b)
+		if digitsA == "" || digitsB == "" {
+			if a[0] != b[0] {
+				return strings.Compare(a[:1], b[:1])
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		numberA, numberB := strings.TrimLeft(digitsA, "0"), strings.TrimLeft(digitsB, "0")
+		if len(numberA) != len(numberB) {
+			return compareBool(len(numberA) > len(numberB), len(numberA) < len(numberB))
+		}
+		if c := strings.Compare(numberA, numberB); c != 0 {
+			return c
+		}
+		a, b = a[len(digitsA):], b[len(digitsB):]
+	}
+	return compareBool(a != "", b != "")
+}
+
+// leadingDigits returns the run of ASCII digits at the start of a string
+func leadingDigits(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
+
+// compareBool orders false before true
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,120 @@
+package formatter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/config"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+func TestCompareNatural(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"github.com/acme/api/v2", "github.com/acme/api/v10", -1},
+		{"github.com/acme/api/v10", "github.com/acme/api/v2", 1},
+		{"github.com/acme/api/v02", "github.com/acme/api/v2", 0},
+		{"github.com/acme/api", "github.com/acme/api/v2", -1},
+		{"k8s.io/api", "k8s.io/apimachinery", -1},
+		{"example.com/a9b", "example.com/a10a", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			require.New(t).Equal(tt.expected, compareNatural(tt.a, tt.b))
+		})
+	}
+}
+
+func TestFormatter_SortKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		orgs     []string
+		sort     map[string][]string
+		src      string
+		expected string
+	}{
+		{
+			name:     "path by default",
+			src:      "package main\n\nimport (\n\t\"github.com/acme/api/v2\"\n\t\"github.com/acme/api/v10\"\n)\n",
+			expected: "package main\n\nimport (\n\t\"github.com/acme/api/v10\"\n\t\"github.com/acme/api/v2\"\n)\n",
+		},
+		{
+			name:     "natural",
+			sort:     map[string][]string{config.SortGroupThirdParty: {config.SortNatural}},
+			src:      "package main\n\nimport (\n\t\"github.com/acme/api/v10\"\n\t\"github.com/acme/api/v2\"\n)\n",
+			expected: "package main\n\nimport (\n\t\"github.com/acme/api/v2\"\n\t\"github.com/acme/api/v10\"\n)\n",
+		},
+		{
+			name:     "case insensitive",
+			sort:     map[string][]string{config.SortGroupDefault: {config.SortCaseInsensitive}},
+			src:      "package main\n\nimport (\n\t\"github.com/acme/zap\"\n\t\"github.com/Acme/log\"\n)\n",
+			expected: "package main\n\nimport (\n\t\"github.com/Acme/log\"\n\t\"github.com/acme/zap\"\n)\n",
+		},
+		{
+			name:     "alias",
+			sort:     map[string][]string{config.SortGroupThirdParty: {config.SortAlias}},
+			src:      "package main\n\nimport (\n\tb \"github.com/acme/a\"\n\t\"github.com/acme/c\"\n\ta \"github.com/acme/d\"\n)\n",
+			expected: "package main\n\nimport (\n\ta \"github.com/acme/d\"\n\tb \"github.com/acme/a\"\n\t\"github.com/acme/c\"\n)\n",
+		},
+		{
+			name:     "aliased last",
+			sort:     map[string][]string{config.SortGroupStd: {config.SortAliasedLast}},
+			src:      "package main\n\nimport (\n\t_ \"embed\"\n\tstr \"strings\"\n\t\"os\"\n\t\"fmt\"\n)\n",
+			expected: "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n\t_ \"embed\"\n\tstr \"strings\"\n)\n",
+		},
+		{
+			name: "organization groups",
+			orgs: []string{"github.com/other", "github.com/myorg"},
+			sort: map[string][]string{
+				config.SortGroupOrg:        {config.SortNatural},
+				"org:github.com/myorg":     {config.SortAliasedLast},
+				config.SortGroupDefault:    {config.SortAlias},
+				config.SortGroupThirdParty: {config.SortPath},
+			},
+			src:      "package main\n\nimport (\n\tx \"github.com/myorg/app/a\"\n\t\"github.com/myorg/app/b\"\n\n\t\"github.com/other/app/v10\"\n\t\"github.com/other/app/v9\"\n)\n",
+			expected: "package main\n\nimport (\n\t\"github.com/other/app/v9\"\n\t\"github.com/other/app/v10\"\n\n\t\"github.com/myorg/app/b\"\n\tx \"github.com/myorg/app/a\"\n)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			g := New(FormatterConfig{
+				FilePath:       "main.go",
+				Orgs:           tt.orgs,
+				CurrentProject: "github.com/test/project",
+				Sort:           tt.sort,
+			})
+			result, err := g.formatSource([]byte(tt.src))
+			req.NoError(err)
+			req.Equal(tt.expected, string(result.output))
+		})
+	}
+}
+
+func TestFormatter_FindViolations_SortKeys(t *testing.T) {
+	req := require.New(t)
+	src := "package main\n\nimport (\n\t\"github.com/acme/api/v2\"\n\t\"github.com/acme/api/v10\"\n)\n"
+
+	g := New(FormatterConfig{FilePath: "main.go", CurrentProject: "github.com/test/project", Check: true})
+	result, err := g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Equal(report.StatusChanged, result.status)
+	req.Len(result.violations, 1)
+	req.Equal(report.RuleUnsorted, result.violations[0].Rule)
+
+	g = New(FormatterConfig{
+		FilePath:       "main.go",
+		CurrentProject: "github.com/test/project",
+		Check:          true,
+		Sort:           map[string][]string{config.SortGroupThirdParty: {config.SortNatural}},
+	})
+	result, err = g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Equal(report.StatusUnchanged, result.status)
+	req.Empty(result.violations)
+}
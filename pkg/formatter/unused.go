@@ -0,0 +1,95 @@
+package formatter
+
+import (
+	"go/ast"
+	"strings"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/resolver"
+)
+
+// unusedImports returns the paths of the imports that are not referenced by the file.
+// Without type information, an import is used when the qualifier of a selector
+// expression matches its name: its alias, or the name assumed from its path.
+// Blank, dot and cgo imports are always used.
+//
+// The assumed name of a non-standard package may be wrong, e.g. for a package whose
+// name does not match its directory. Such imports are only reported as unused when
+// every qualifier of the file is accounted for by another import.
+func (g *formatter) unusedImports(file *ast.File) map[string]bool {
+	qualifiers := usedQualifiers(file)
+
+	matched := make(map[string]bool)
+	var candidates []*ast.ImportSpec
+	for _, spec := range file.Imports {
+		importPath := strings.Trim(spec.Path.Value, `"`)
+		if importPath == "C" {
+			continue
+		}
+
+		name := ""
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		switch name {
+		case "_", ".":
+			continue
+		case "":
+			name = resolver.AssumedName(importPath)
+		}
+
+		if qualifiers[name] {
+			matched[name] = true
+			continue
+		}
+		candidates = append(candidates, spec)
+	}
+
+	unresolved := false
+	for qualifier := range qualifiers {
+		if !matched[qualifier] {
+			unresolved = true
+			break
+		}
+	}
+
+	unused := make(map[string]bool)
+	for _, spec := range candidates {
+		importPath := strings.Trim(spec.Path.Value, `"`)
+		if spec.Name == nil && !g.isStdImport(importPath) && unresolved {
+			continue // The qualifier may refer to this import under its actual name
+		}
+		unused[importPath] = true
+	}
+	return unused
+}
+
+// removeUnused drops the imports that are not referenced by the file
+func (g *formatter) removeUnused(file *ast.File, imports []Import) []Import {
+	unused := g.unusedImports(file)
+	if len(unused) == 0 {
+		return imports
+	}
+
+	var used []Import
+	for _, imp := range imports {
+		if !unused[imp.Path] {
+			used = append(used, imp)
+		}
+	}
+	return used
+}
+
+// usedQualifiers collects the identifiers qualifying selector expressions that do not
+// resolve to a declaration of the file, such as package names
+func usedQualifiers(file *ast.File) map[string]bool {
+	qualifiers := make(map[string]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := selector.X.(*ast.Ident); ok && ident.Obj == nil {
+				qualifiers[ident.Name] = true
+			}
+		}
+		return true
+	})
+	return qualifiers
+}
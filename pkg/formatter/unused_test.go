@@ -0,0 +1,194 @@
+package formatter
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/report"
+)
+
+func TestFormatter_RemoveUnused(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name: "unused std and aliased imports",
+			src: `package main
+
+import (
+	"fmt"
+	"os"
+	str "strings"
+	"github.com/test/project/internal"
+)
+
+func main() { fmt.Println(internal.Name) }
+`,
+			expected: `package main
+
+import (
+	"fmt"
+
+	"github.com/test/project/internal"
+)
+
+func main() { fmt.Println(internal.Name) }
+`,
+		},
+		{
+			name: "blank and dot imports are kept",
+			src: `package main
+
+import (
+	_ "embed"
+	. "math"
+	"os"
+)
+
+func main() { _ = Pi }
+`,
+			expected: `package main
+
+import (
+	_ "embed"
+	. "math"
+)
+
+func main() { _ = Pi }
+`,
+		},
+		{
+			name: "versioned paths",
+			src: `package main
+
+import (
+	"github.com/go-redis/redis/v9"
+	"gopkg.in/yaml.v3"
+	"math/rand/v2"
+)
+
+func main() { _ = redis.Nil; _ = yaml.Marshal }
+`,
+			expected: `package main
+
+import (
+	"github.com/go-redis/redis/v9"
+	"gopkg.in/yaml.v3"
+)
+
+func main() { _ = redis.Nil; _ = yaml.Marshal }
+`,
+		},
+		{
+			name: "shadowed package names are not uses",
+			src: `package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+func main() {
+	strings := []string{}
+	_ = strings.Len
+	fmt.Println()
+}
+`,
+			expected: `package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	strings := []string{}
+	_ = strings.Len
+	fmt.Println()
+}
+`,
+		},
+		{
+			name: "unknown qualifiers keep non-standard imports",
+			src: `package main
+
+import (
+	"fmt"
+	"github.com/acme/go-kit-logging"
+)
+
+func main() { fmt.Println(log.Info) }
+`,
+			expected: `package main
+
+import (
+	"fmt"
+
+	"github.com/acme/go-kit-logging"
+)
+
+func main() { fmt.Println(log.Info) }
+`,
+		},
+		{
+			name: "all imports removed",
+			src: `package main
+
+import "os"
+
+func main() {}
+`,
+			expected: `package main
+
+func main() {}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			g := New(FormatterConfig{FilePath: "main.go", CurrentProject: "github.com/test/project", RemoveUnused: true})
+			result, err := g.formatSource([]byte(tt.src))
+			req.NoError(err)
+			req.Equal(tt.expected, string(result.output))
+		})
+	}
+}
+
+func TestFormatter_UnusedImports_Cgo(t *testing.T) {
+	req := require.New(t)
+	src := "package main\n\n// #include <stdio.h>\nimport \"C\"\n\nimport \"os\"\n\nfunc main() {}\n"
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", src, parser.ParseComments)
+	req.NoError(err)
+
+	g := New(FormatterConfig{CurrentProject: "github.com/test/project"})
+	req.Equal(map[string]bool{"os": true}, g.unusedImports(file))
+}
+
+func TestFormatter_FindViolations_Unused(t *testing.T) {
+	req := require.New(t)
+	src := "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() { fmt.Println() }\n"
+
+	g := New(FormatterConfig{FilePath: "main.go", CurrentProject: "github.com/test/project", Check: true, RemoveUnused: true})
+	result, err := g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Equal(report.StatusChanged, result.status)
+	req.Equal([]report.Violation{{
+		Code:    "GIG007",
+		Rule:    report.RuleUnusedImport,
+		Message: `"os" is not used`,
+		Line:    5,
+		Column:  2,
+	}}, result.violations)
+
+	// Unused imports are only reported when they are removed
+	g = New(FormatterConfig{FilePath: "main.go", CurrentProject: "github.com/test/project", Check: true})
+	result, err = g.formatSource([]byte(src))
+	req.NoError(err)
+	req.Empty(result.violations)
+}
@@ -0,0 +1,176 @@
+package hook
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/config"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/utils"
+)
+
+// FrameworkConfigName is the configuration file of the pre-commit framework (https://pre-commit.com)
+const FrameworkConfigName = ".pre-commit-config.yaml"
+
+// frameworkHookID is the id of the gig hook, matching .pre-commit-hooks.yaml
+const frameworkHookID = "gig"
+
+// frameworkRepo is a local repository entry of the pre-commit framework configuration
+type frameworkRepo struct {
+	Repo  string          `yaml:"repo"`
+	Hooks []frameworkHook `yaml:"hooks"`
+}
+
+type frameworkHook struct {
+	ID       string   `yaml:"id"`
+	Name     string   `yaml:"name"`
+	Entry    string   `yaml:"entry"`
+	Language string   `yaml:"language"`
+	Types    []string `yaml:"types"`
+}
+
+// The framework stashes unstaged changes and reports the files modified by hooks
+// itself, so the formatted files are not staged again
+var localRepo = frameworkRepo{
+	Repo: "local",
+	Hooks: []frameworkHook{{
+		ID:       frameworkHookID,
+		Name:     "gig",
+		Entry:    "gig hook run --no-stage",
+		Language: "system",
+		Types:    []string{"go"},
+	}},
+}
+
+// InstallFramework adds the gig hook to the pre-commit framework configuration at the
+// root of the git working tree containing dir, creating the file when missing.
+// It returns the path of the configuration file.
+func InstallFramework(dir string) (string, error) {
+	path, root, err := readFrameworkConfig(dir)
+	if err != nil {
+		return "", err
+	}
+
+	mapping := root.Content[0]
+	repos := config.MappingValue(mapping, "repos")
+	if repos == nil {
+		repos = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "repos"}, repos)
+	}
+	for _, repo := range repos.Content {
+		for _, hook := range localHooks(repo) {
+			if isGigHook(hook) {
+				return path, nil // Already installed
+			}
+		}
+	}
+
+	var repo yaml.Node
+	if err := repo.Encode(localRepo); err != nil {
+		return "", err
+	}
+	repos.Content = append(repos.Content, &repo)
+	return path, writeFrameworkConfig(path, root)
+}
+
+// UninstallFramework removes the gig hook from the pre-commit framework configuration,
+// along with its repository entry when it has no other hooks
+func UninstallFramework(dir string) (string, error) {
+	path, root, err := readFrameworkConfig(dir)
+	if err != nil {
+		return "", err
+	}
+
+	repos := config.MappingValue(root.Content[0], "repos")
+	found := false
+	if repos != nil {
+		var keptRepos []*yaml.Node
+		for _, repo := range repos.Content {
+			var keptHooks []*yaml.Node
+			removed := false
+			for _, hook := range localHooks(repo) {
+				if isGigHook(hook) {
+					removed = true
+					continue
+				}
+				keptHooks = append(keptHooks, hook)
+			}
+			if removed {
+				found = true
+				if len(keptHooks) == 0 {
+					continue
+				}
+				config.MappingValue(repo, "hooks").Content = keptHooks
+			}
+			keptRepos = append(keptRepos, repo)
+		}
+		repos.Content = keptRepos
+	}
+	if !found {
+		return "", fmt.Errorf("%s: %s", errors.ErrMsgHookNotInstalled, path)
+	}
+	return path, writeFrameworkConfig(path, root)
+}
+
+// readFrameworkConfig reads the framework configuration as a document with a
+// top-level mapping, an empty one when the file does not exist
+func readFrameworkConfig(dir string) (string, *yaml.Node, error) {
+	topLevel, err := utils.GitTopLevel(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	path := filepath.Join(topLevel, FrameworkConfigName)
+
+	root := &yaml.Node{}
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", nil, &errors.ReadError{Path: path, Err: err}
+	}
+	if err := yaml.Unmarshal(content, root); err != nil {
+		return "", nil, &errors.ConfigError{Path: path, Err: err}
+	}
+	if root.Kind == 0 {
+		root = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return "", nil, &errors.ConfigError{Path: path, Err: fmt.Errorf(errors.ErrMsgNotAMapping)}
+	}
+	return path, root, nil
+}
+
+func writeFrameworkConfig(path string, root *yaml.Node) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return &errors.WriteError{Path: path, Err: err}
+	}
+	return nil
+}
+
+// localHooks returns the hooks of a local repository entry
+func localHooks(repo *yaml.Node) []*yaml.Node {
+	if value := config.MappingValue(repo, "repo"); value == nil || value.Value != "local" {
+		return nil
+	}
+	if hooks := config.MappingValue(repo, "hooks"); hooks != nil {
+		return hooks.Content
+	}
+	return nil
+}
+
+// isGigHook checks if a hook entry runs gig
+func isGigHook(hook *yaml.Node) bool {
+	id := config.MappingValue(hook, "id")
+	return id != nil && id.Value == frameworkHookID
+}
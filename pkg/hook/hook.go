@@ -0,0 +1,118 @@
+// Package hook installs gig as a git pre-commit hook and runs it on the staged Go files
+package hook
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/utils"
+)
+
+const (
+	// Name is the name of the git hook running gig
+	Name = "pre-commit"
+
+	// ChainedSuffix is appended to the name of an existing hook chained before gig
+	ChainedSuffix = ".gig-chained"
+
+	// marker identifies the hooks installed by gig, other hooks are never overwritten
+	marker = "# gig:managed"
+)
+
+// script is the pre-commit hook, running the chained hook first when there is one
+var script = `#!/bin/sh
+` + marker + ` - installed by "gig hook install", remove it with "gig hook uninstall"
+chained="$(dirname "$0")/` + Name + ChainedSuffix + `"
+if [ -x "$chained" ]; then
+	"$chained" "$@" || exit $?
+fi
+exec gig hook run
+`
+
+// InstallResult describes what Install did
+type InstallResult struct {
+	Path        string // path of the installed hook
+	ChainedPath string // path the existing hook was moved to, empty when none
+}
+
+// Install writes the pre-commit hook in the git repository containing dir. An existing
+// hook that was not installed by gig is refused, unless chain is set to move it aside
+// and run it before gig.
+func Install(dir string, chain bool) (*InstallResult, error) {
+	hooksDir, err := utils.GitHooksDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	result := &InstallResult{Path: filepath.Join(hooksDir, Name)}
+
+	content, err := os.ReadFile(result.Path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, &errors.ReadError{Path: result.Path, Err: err}
+	case !isManaged(content):
+		if !chain {
+			return nil, fmt.Errorf("%s: %s", errors.ErrMsgHookExists, result.Path)
+		}
+		chainedPath := result.Path + ChainedSuffix
+		if _, err := os.Stat(chainedPath); err == nil {
+			return nil, fmt.Errorf("%s: %s", errors.ErrMsgChainedHookExists, chainedPath)
+		}
+		if err := os.Rename(result.Path, chainedPath); err != nil {
+			return nil, err
+		}
+		result.ChainedPath = chainedPath
+	}
+
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(result.Path, []byte(script), 0755); err != nil {
+		return nil, &errors.WriteError{Path: result.Path, Err: err}
+	}
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(result.Path, 0755); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UninstallResult describes what Uninstall did
+type UninstallResult struct {
+	Path     string // path of the removed hook
+	Restored bool   // whether the chained hook was moved back to Path
+}
+
+// Uninstall removes the pre-commit hook installed by gig, restoring the hook it chained
+func Uninstall(dir string) (*UninstallResult, error) {
+	hooksDir, err := utils.GitHooksDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	result := &UninstallResult{Path: filepath.Join(hooksDir, Name)}
+
+	content, err := os.ReadFile(result.Path)
+	if err != nil || !isManaged(content) {
+		return nil, fmt.Errorf("%s: %s", errors.ErrMsgHookNotInstalled, result.Path)
+	}
+	if err := os.Remove(result.Path); err != nil {
+		return nil, err
+	}
+
+	chainedPath := result.Path + ChainedSuffix
+	if _, err := os.Stat(chainedPath); err == nil {
+		if err := os.Rename(chainedPath, result.Path); err != nil {
+			return nil, err
+		}
+		result.Restored = true
+	}
+	return result, nil
+}
+
+// isManaged checks if a hook was installed by gig
+func isManaged(content []byte) bool {
+	return bytes.Contains(content, []byte(marker))
+}
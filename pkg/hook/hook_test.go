@@ -0,0 +1,180 @@
+package hook
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/formatter"
+)
+
+const (
+	misgroupedSource = "package main\n\nimport (\n\t\"os\"\n\t\"fmt\"\n)\n\nfunc main() { fmt.Println(os.Args) }\n"
+	groupedSource    = "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() { fmt.Println(os.Args) }\n"
+)
+
+// initGitRepo creates a throwaway git repository with an initial commit
+func initGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	repoDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "go.mod"), []byte("module github.com/test/project\n"), 0644))
+	git(t, repoDir, "init", "-q")
+	git(t, repoDir, "config", "user.email", "test@example.com")
+	git(t, repoDir, "config", "user.name", "Test")
+	git(t, repoDir, "config", "commit.gpgsign", "false")
+	git(t, repoDir, "add", "-A")
+	git(t, repoDir, "commit", "-q", "-m", "initial")
+	return repoDir
+}
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, out)
+	return string(out)
+}
+
+func TestInstall(t *testing.T) {
+	req := require.New(t)
+	repoDir := initGitRepo(t)
+	hookPath := filepath.Join(repoDir, ".git", "hooks", Name)
+
+	result, err := Install(repoDir, false)
+	req.NoError(err)
+	req.Equal(hookPath, result.Path)
+	req.Empty(result.ChainedPath)
+	info, err := os.Stat(hookPath)
+	req.NoError(err)
+	req.NotZero(info.Mode()&0100, "hook is executable")
+
+	// Installing again updates the hook
+	_, err = Install(repoDir, false)
+	req.NoError(err)
+
+	uninstalled, err := Uninstall(repoDir)
+	req.NoError(err)
+	req.False(uninstalled.Restored)
+	req.NoFileExists(hookPath)
+
+	_, err = Uninstall(repoDir)
+	req.Error(err)
+}
+
+func TestInstall_Chain(t *testing.T) {
+	req := require.New(t)
+	repoDir := initGitRepo(t)
+	hookPath := filepath.Join(repoDir, ".git", "hooks", Name)
+	existing := "#!/bin/sh\nexit 0\n"
+	req.NoError(os.WriteFile(hookPath, []byte(existing), 0755))
+
+	// Existing hooks are not clobbered
+	_, err := Install(repoDir, false)
+	req.Error(err)
+	content, err := os.ReadFile(hookPath)
+	req.NoError(err)
+	req.Equal(existing, string(content))
+
+	// Nor removed
+	_, err = Uninstall(repoDir)
+	req.Error(err)
+
+	result, err := Install(repoDir, true)
+	req.NoError(err)
+	req.Equal(hookPath+ChainedSuffix, result.ChainedPath)
+	content, err = os.ReadFile(result.ChainedPath)
+	req.NoError(err)
+	req.Equal(existing, string(content))
+
+	uninstalled, err := Uninstall(repoDir)
+	req.NoError(err)
+	req.True(uninstalled.Restored)
+	content, err = os.ReadFile(hookPath)
+	req.NoError(err)
+	req.Equal(existing, string(content))
+	req.NoFileExists(hookPath + ChainedSuffix)
+}
+
+func TestInstallFramework(t *testing.T) {
+	req := require.New(t)
+	repoDir := initGitRepo(t)
+	configPath := filepath.Join(repoDir, FrameworkConfigName)
+	original := "# Hooks of the project\nrepos:\n  - repo: https://github.com/pre-commit/pre-commit-hooks\n    rev: v4.6.0\n    hooks:\n      - id: trailing-whitespace\n"
+	req.NoError(os.WriteFile(configPath, []byte(original), 0644))
+
+	path, err := InstallFramework(repoDir)
+	req.NoError(err)
+	req.Equal(configPath, path)
+	content, err := os.ReadFile(configPath)
+	req.NoError(err)
+	req.True(strings.HasPrefix(string(content), original), "existing entries are kept:\n%s", content)
+	req.Contains(string(content), "  - repo: local\n    hooks:\n      - id: gig\n")
+
+	// Installing again does not duplicate the entry
+	_, err = InstallFramework(repoDir)
+	req.NoError(err)
+	installed, err := os.ReadFile(configPath)
+	req.NoError(err)
+	req.Equal(string(content), string(installed))
+
+	_, err = UninstallFramework(repoDir)
+	req.NoError(err)
+	content, err = os.ReadFile(configPath)
+	req.NoError(err)
+	req.Equal(original, string(content))
+
+	_, err = UninstallFramework(repoDir)
+	req.Error(err)
+}
+
+func TestRun(t *testing.T) {
+	req := require.New(t)
+	repoDir := initGitRepo(t)
+	staged := filepath.Join(repoDir, "staged.go")
+	partial := filepath.Join(repoDir, "partial.go")
+	req.NoError(os.WriteFile(staged, []byte(misgroupedSource), 0644))
+	req.NoError(os.WriteFile(partial, []byte(misgroupedSource), 0644))
+	git(t, repoDir, "add", "staged.go", "partial.go")
+	req.NoError(os.WriteFile(partial, []byte(misgroupedSource+"\nvar x = 1\n"), 0644))
+
+	// Check mode leaves the files untouched
+	var output bytes.Buffer
+	err := Run(repoDir, formatter.FormatterConfig{Check: true}, RunOptions{Output: &output})
+	req.Error(err)
+	content, err := os.ReadFile(staged)
+	req.NoError(err)
+	req.Equal(misgroupedSource, string(content))
+
+	// Fix mode formats and stages fully staged files, partially staged files are only checked
+	output.Reset()
+	err = Run(repoDir, formatter.FormatterConfig{}, RunOptions{Output: &output})
+	req.Error(err)
+	req.Contains(err.Error(), "unstaged changes")
+	req.Contains(output.String(), "Staged 1 formatted files")
+
+	content, err = os.ReadFile(staged)
+	req.NoError(err)
+	req.Equal(groupedSource, string(content))
+	req.Equal(groupedSource, git(t, repoDir, "show", ":staged.go"))
+	req.Equal(misgroupedSource, git(t, repoDir, "show", ":partial.go"))
+	content, err = os.ReadFile(partial)
+	req.NoError(err)
+	req.Equal(misgroupedSource+"\nvar x = 1\n", string(content))
+
+	// Without staging, as run by the pre-commit framework
+	req.NoError(os.WriteFile(partial, []byte(misgroupedSource), 0644))
+	err = Run(repoDir, formatter.FormatterConfig{}, RunOptions{Files: []string{partial, filepath.Join(repoDir, "go.mod")}, NoStage: true, Output: &output})
+	req.NoError(err)
+	content, err = os.ReadFile(partial)
+	req.NoError(err)
+	req.Equal(groupedSource, string(content))
+	req.Equal(misgroupedSource, git(t, repoDir, "show", ":partial.go"))
+}
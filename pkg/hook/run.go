@@ -0,0 +1,125 @@
+package hook
+
+import (
+	"bytes"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/formatter"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/utils"
+)
+
+// RunOptions selects the files processed by Run
+type RunOptions struct {
+	Files   []string  // files to process, the staged Go files under the directory when empty
+	NoStage bool      // leave the formatted files unstaged
+	Output  io.Writer // destination of the output, stdout when nil
+}
+
+// Run groups the imports of the staged Go files under dir and stages the files it
+// formats. Files with unstaged changes are only checked, since staging them would
+// commit changes the user did not stage. With config.Check set, all files are
+// only checked and the commit fails when any of them has misgrouped imports.
+func Run(dir string, config formatter.FormatterConfig, options RunOptions) error {
+	files := options.Files
+	if len(files) == 0 {
+		staged, err := utils.FindStagedGoFiles(dir)
+		if err != nil {
+			return fmt.Errorf("%s: %w", errors.ErrMsgFailedToFindGitFiles, err)
+		}
+		files = staged
+	} else {
+		files = goFiles(files)
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	config.Output = options.Output
+	config.InPlace = false
+	if config.Check {
+		return formatter.New(config).ProcessFiles(files)
+	}
+
+	unstaged, err := utils.FindUnstagedGoFiles(dir)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errors.ErrMsgFailedToFindGitFiles, err)
+	}
+	partial := make(map[string]bool)
+	for _, file := range unstaged {
+		partial[absPath(file)] = true
+	}
+	var fixFiles, checkFiles []string
+	for _, file := range files {
+		if partial[absPath(file)] {
+			checkFiles = append(checkFiles, file)
+		} else {
+			fixFiles = append(fixFiles, file)
+		}
+	}
+
+	var errs []error
+	if len(fixFiles) > 0 {
+		if err := fix(dir, config, fixFiles, options); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(checkFiles) > 0 {
+		checkConfig := config
+		checkConfig.Check = true
+		if err := formatter.New(checkConfig).ProcessFiles(checkFiles); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", errors.ErrMsgPartiallyStaged, err))
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+// fix groups the imports of files in place and stages the ones that changed
+func fix(dir string, config formatter.FormatterConfig, files []string, options RunOptions) error {
+	before := make(map[string][]byte, len(files))
+	for _, file := range files {
+		before[file], _ = os.ReadFile(file)
+	}
+
+	config.InPlace = true
+	err := formatter.New(config).ProcessFiles(files)
+
+	var changed []string
+	for _, file := range files {
+		if content, readErr := os.ReadFile(file); readErr == nil && !bytes.Equal(content, before[file]) {
+			changed = append(changed, absPath(file))
+		}
+	}
+	if len(changed) > 0 && !options.NoStage {
+		if addErr := utils.GitAdd(dir, changed...); addErr != nil {
+			return stderrors.Join(err, addErr)
+		}
+		output := options.Output
+		if output == nil {
+			output = os.Stdout
+		}
+		fmt.Fprintf(output, errors.InfoMsgRestagedFiles+"\n", len(changed))
+	}
+	return err
+}
+
+func goFiles(files []string) []string {
+	var filtered []string
+	for _, file := range files {
+		if utils.IsGoFile(file) {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,274 @@
+// Package journal records the files modified by in-place runs so that a run can be undone,
+// even when the files are not under version control
+package journal
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/utils"
+)
+
+const (
+	// CacheDirEnv overrides the directory the journals are written to
+	CacheDirEnv = "GIG_CACHE_DIR"
+
+	// MaxRuns is the number of runs kept, older journals are removed
+	MaxRuns = 10
+
+	entriesFile = "journal.ndjson"
+	blobsDir    = "blobs"
+	idLayout    = "20060102T150405.000000000Z"
+)
+
+// DefaultDir returns the directory of the journals, $GIG_CACHE_DIR or gig/journal
+// under the user cache directory
+func DefaultDir() (string, error) {
+	if dir := os.Getenv(CacheDirEnv); dir != "" {
+		return dir, nil
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "gig", "journal"), nil
+}
+
+// Entry records a file modified by a run
+type Entry struct {
+	Path      string `json:"path"`             // absolute path of the file
+	Original  string `json:"original"`         // SHA-256 of the content before the run
+	Formatted string `json:"formatted"`        // SHA-256 of the content written by the run
+	Backup    string `json:"backup,omitempty"` // path of the backup kept next to the file, if any
+}
+
+// Run is the journal of a run
+type Run struct {
+	ID      string    // identifier of the run, ordered by time
+	Time    time.Time // start of the run
+	Entries []Entry   // modified files, in the order they were written
+}
+
+// Recorder writes the journal of a run. The journal is created with the first
+// recorded file and each entry is appended as soon as it is recorded, so that an
+// interrupted run can still be undone.
+type Recorder struct {
+	mu     sync.Mutex
+	dir    string
+	runDir string
+	file   *os.File
+}
+
+// NewRecorder creates a recorder writing a new run under dir
+func NewRecorder(dir string) *Recorder {
+	return &Recorder{dir: dir}
+}
+
+// Record stores the original content of a file about to be replaced by formatted,
+// along with the path of its backup if one is kept
+func (r *Recorder) Record(path string, original, formatted []byte, backup string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		if err := r.create(); err != nil {
+			return err
+		}
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	entry := Entry{Path: absPath, Original: hash(original), Formatted: hash(formatted), Backup: backup}
+	blobPath := filepath.Join(r.runDir, blobsDir, entry.Original)
+	if err := os.WriteFile(blobPath, original, 0600); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return r.file.Sync()
+}
+
+// create creates the directory of the run and removes the oldest runs
+func (r *Recorder) create() error {
+	id := time.Now().UTC().Format(idLayout)
+	r.runDir = filepath.Join(r.dir, id)
+	if err := os.MkdirAll(filepath.Join(r.runDir, blobsDir), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filepath.Join(r.runDir, entriesFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	r.file = file
+	return prune(r.dir, id)
+}
+
+// Close closes the journal, the run can be undone from then on
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// List returns the recorded runs, the most recent first
+func List(dir string) ([]*Run, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var runs []*Run
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() {
+			continue
+		}
+		run, err := Load(dir, dirEntry.Name())
+		if err != nil {
+			continue // Not a run, or one without a journal
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].ID > runs[j].ID })
+	return runs, nil
+}
+
+// Load reads the journal of a run
+func Load(dir, id string) (*Run, error) {
+	runTime, err := time.Parse(idLayout, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %q", errors.ErrMsgUnknownRun, id)
+	}
+	file, err := os.Open(filepath.Join(dir, id, entriesFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s: %q", errors.ErrMsgUnknownRun, id)
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	run := &Run{ID: id, Time: runTime}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			break // Entry cut short by an interrupted run
+		}
+		run.Entries = append(run.Entries, entry)
+	}
+	return run, scanner.Err()
+}
+
+// UndoResult lists the files of an undone run
+type UndoResult struct {
+	Restored  []string // files restored to their original content
+	Unchanged []string // files already holding their original content
+	Edited    []string // files edited after the run, left untouched
+	Missing   []string // files removed after the run
+}
+
+// Undo restores the files modified by a run. Files edited after the run are not
+// overwritten, and the journal is only removed once every file is restored.
+func Undo(dir string, run *Run) (*UndoResult, error) {
+	result := &UndoResult{}
+	runDir := filepath.Join(dir, run.ID)
+
+	// Restore in reverse order, files written twice end up with their first original
+	for i := len(run.Entries) - 1; i >= 0; i-- {
+		entry := run.Entries[i]
+		current, err := os.ReadFile(entry.Path)
+		switch {
+		case os.IsNotExist(err):
+			result.Missing = append(result.Missing, entry.Path)
+			continue
+		case err != nil:
+			return result, &errors.ReadError{Path: entry.Path, Err: err}
+		}
+
+		switch hash(current) {
+		case entry.Original:
+			result.Unchanged = append(result.Unchanged, entry.Path)
+			continue
+		case entry.Formatted:
+		default:
+			result.Edited = append(result.Edited, entry.Path)
+			continue
+		}
+
+		original, err := os.ReadFile(filepath.Join(runDir, blobsDir, entry.Original))
+		if err != nil {
+			return result, err
+		}
+		if _, err := utils.WriteFileAtomic(entry.Path, original); err != nil {
+			return result, &errors.WriteError{Path: entry.Path, Err: err}
+		}
+		result.Restored = append(result.Restored, entry.Path)
+		removeBackup(entry)
+	}
+
+	if len(result.Edited) > 0 {
+		return result, fmt.Errorf(errors.ErrMsgUndoEditedFiles, len(result.Edited))
+	}
+	return result, os.RemoveAll(runDir)
+}
+
+// removeBackup removes the backup of a restored file when it still holds the original
+func removeBackup(entry Entry) {
+	if entry.Backup == "" {
+		return
+	}
+	if content, err := os.ReadFile(entry.Backup); err == nil && hash(content) == entry.Original {
+		os.Remove(entry.Backup)
+	}
+}
+
+// prune removes the oldest runs, keeping MaxRuns including the current one
+func prune(dir, current string) error {
+	runs, err := List(dir)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	kept := 0
+	for _, run := range runs {
+		if run.ID == current {
+			continue
+		}
+		kept++
+		if kept >= MaxRuns {
+			errs = append(errs, os.RemoveAll(filepath.Join(dir, run.ID)))
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+func hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
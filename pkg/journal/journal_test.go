@@ -0,0 +1,119 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/testutil"
+)
+
+// record journals the replacement of files as an in-place run would
+func record(t *testing.T, journalDir, dir string, files map[string]string) {
+	t.Helper()
+	req := require.New(t)
+	recorder := NewRecorder(journalDir)
+	for name, formatted := range files {
+		path := filepath.Join(dir, name)
+		original, err := os.ReadFile(path)
+		req.NoError(err)
+		req.NoError(recorder.Record(path, original, []byte(formatted), ""))
+		req.NoError(os.WriteFile(path, []byte(formatted), 0644))
+	}
+	req.NoError(recorder.Close())
+}
+
+func TestUndo(t *testing.T) {
+	req := require.New(t)
+	journalDir := t.TempDir()
+	dir := t.TempDir()
+	testutil.WriteFiles(t, dir, map[string]string{"a.go": "a original", "b.go": "b original", "c.go": "c original", "d.go": "d original"})
+	record(t, journalDir, dir, map[string]string{"a.go": "a formatted", "b.go": "b formatted", "c.go": "c formatted", "d.go": "d formatted"})
+
+	runs, err := List(journalDir)
+	req.NoError(err)
+	req.Len(runs, 1)
+	req.Len(runs[0].Entries, 4)
+
+	// b.go is edited after the run, c.go is reverted and d.go is removed
+	testutil.WriteFiles(t, dir, map[string]string{"b.go": "b edited", "c.go": "c original"})
+	req.NoError(os.Remove(filepath.Join(dir, "d.go")))
+
+	result, err := Undo(journalDir, runs[0])
+	req.Error(err)
+	req.Equal([]string{filepath.Join(dir, "a.go")}, result.Restored)
+	req.Equal([]string{filepath.Join(dir, "b.go")}, result.Edited)
+	req.Equal([]string{filepath.Join(dir, "c.go")}, result.Unchanged)
+	req.Equal([]string{filepath.Join(dir, "d.go")}, result.Missing)
+
+	content, err := os.ReadFile(filepath.Join(dir, "a.go"))
+	req.NoError(err)
+	req.Equal("a original", string(content))
+	content, err = os.ReadFile(filepath.Join(dir, "b.go"))
+	req.NoError(err)
+	req.Equal("b edited", string(content), "edited files are not overwritten")
+
+	// The journal is kept until every file could be restored
+	runs, err = List(journalDir)
+	req.NoError(err)
+	req.Len(runs, 1)
+
+	testutil.WriteFiles(t, dir, map[string]string{"b.go": "b formatted"})
+	result, err = Undo(journalDir, runs[0])
+	req.NoError(err)
+	req.Equal([]string{filepath.Join(dir, "b.go")}, result.Restored)
+	runs, err = List(journalDir)
+	req.NoError(err)
+	req.Empty(runs)
+}
+
+func TestUndo_Backup(t *testing.T) {
+	req := require.New(t)
+	journalDir := t.TempDir()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.go")
+	backup := path + ".orig"
+	testutil.WriteFiles(t, dir, map[string]string{"a.go": "formatted", "a.go.orig": "original"})
+
+	recorder := NewRecorder(journalDir)
+	req.NoError(recorder.Record(path, []byte("original"), []byte("formatted"), backup))
+	req.NoError(recorder.Close())
+
+	_, err := Undo(journalDir, mustLatest(t, journalDir))
+	req.NoError(err)
+	req.NoFileExists(backup, "backups holding the original are removed")
+}
+
+func TestRecorder_Prune(t *testing.T) {
+	req := require.New(t)
+	journalDir := t.TempDir()
+	dir := t.TempDir()
+	testutil.WriteFiles(t, dir, map[string]string{"a.go": "0"})
+
+	for i := 1; i <= MaxRuns+2; i++ {
+		record(t, journalDir, dir, map[string]string{"a.go": string(rune('0' + i))})
+	}
+	runs, err := List(journalDir)
+	req.NoError(err)
+	req.Len(runs, MaxRuns)
+	req.True(runs[0].ID > runs[1].ID, "most recent run first")
+
+	// A run without files has no journal
+	req.NoError(NewRecorder(journalDir).Close())
+	runs, err = List(journalDir)
+	req.NoError(err)
+	req.Len(runs, MaxRuns)
+
+	_, err = Load(journalDir, "unknown")
+	req.Error(err)
+}
+
+func mustLatest(t *testing.T, journalDir string) *Run {
+	t.Helper()
+	runs, err := List(journalDir)
+	require.NoError(t, err)
+	require.NotEmpty(t, runs)
+	return runs[0]
+}
@@ -0,0 +1,326 @@
+// Package lsp implements a minimal Language Server Protocol server grouping the
+// imports of the documents on formatting and through the organize imports code action
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode/utf16"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/formatter"
+)
+
+// Options configures the grouping of the documents, taking precedence over the
+// configuration files of the workspace folders
+type Options struct {
+	Orgs           []string `json:"orgs,omitempty"`
+	CurrentProject string   `json:"currentProject,omitempty"`
+}
+
+// Server serves a single client over a pair of streams, usually stdin and stdout
+type Server struct {
+	in      *bufio.Reader
+	out     io.Writer
+	version string
+
+	mu         sync.Mutex
+	options    Options
+	folders    []string              // paths of the workspace folders
+	workspaces map[string]*workspace // settings cached per workspace folder
+	documents  map[string]string     // content of the open documents by URI
+	shutdown   bool
+}
+
+// NewServer creates a server reading requests from in and writing responses to out
+func NewServer(in io.Reader, out io.Writer, version string, options Options) *Server {
+	return &Server{
+		in:         bufio.NewReader(in),
+		out:        out,
+		version:    version,
+		options:    options,
+		workspaces: make(map[string]*workspace),
+		documents:  make(map[string]string),
+	}
+}
+
+// Serve handles messages until the client sends the exit notification or closes the stream
+func (s *Server) Serve() error {
+	for {
+		msg, err := readMessage(s.in)
+		if err != nil {
+			if stderrors.Is(err, io.EOF) {
+				return nil
+			}
+			var rpcErr *rpcError
+			if stderrors.As(err, &rpcErr) {
+				if err := s.reply(json.RawMessage("null"), nil, rpcErr); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf(errors.ErrMsgExitWithoutShutdown)
+			}
+			return nil
+		}
+
+		result, err := s.handle(msg)
+		if !msg.isRequest() {
+			continue
+		}
+		if err := s.reply(msg.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a request or a notification, the result is ignored for notifications
+func (s *Server) handle(msg *message) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shutdown && msg.Method != "shutdown" {
+		return nil, &rpcError{Code: codeInvalidRequest, Message: errors.ErrMsgServerShutDown}
+	}
+
+	switch msg.Method {
+	case "initialize":
+		var params initializeParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.initialize(&params), nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		s.documents[params.TextDocument.URI] = params.TextDocument.Text
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		// Documents are synchronized in full, the last change holds the whole content
+		if n := len(params.ContentChanges); n > 0 {
+			s.documents[params.TextDocument.URI] = params.ContentChanges[n-1].Text
+		}
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		delete(s.documents, params.TextDocument.URI)
+	case "workspace/didChangeWorkspaceFolders":
+		var params didChangeWorkspaceFoldersParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		s.changeFolders(params.Event.Added, params.Event.Removed)
+	case "workspace/didChangeWatchedFiles":
+		// Configuration files or go.mod may have changed, settings are resolved again
+		s.workspaces = make(map[string]*workspace)
+	case "textDocument/formatting":
+		var params documentFormattingParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.format(params.TextDocument.URI)
+	case "textDocument/codeAction":
+		var params codeActionParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.codeActions(&params)
+	default:
+		if msg.isRequest() {
+			return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("%s: %s", errors.ErrMsgMethodNotFound, msg.Method)}
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) initialize(params *initializeParams) *initializeResult {
+	if params.InitializationOptions != nil {
+		if len(params.InitializationOptions.Orgs) > 0 {
+			s.options.Orgs = params.InitializationOptions.Orgs
+		}
+		if params.InitializationOptions.CurrentProject != "" {
+			s.options.CurrentProject = params.InitializationOptions.CurrentProject
+		}
+	}
+
+	folders := params.WorkspaceFolders
+	if len(folders) == 0 && params.RootURI != "" {
+		folders = []workspaceFolder{{URI: params.RootURI}}
+	}
+	s.changeFolders(folders, nil)
+
+	return &initializeResult{
+		Capabilities: serverCapabilities{
+			TextDocumentSync:           textDocumentSyncFull,
+			DocumentFormattingProvider: true,
+			CodeActionProvider:         codeActionOptions{CodeActionKinds: []string{codeActionKindOrganizeImports}},
+			Workspace: workspaceCapabilities{
+				WorkspaceFolders: workspaceFoldersCapabilities{Supported: true, ChangeNotifications: true},
+			},
+		},
+		ServerInfo: serverInfo{Name: "gig", Version: s.version},
+	}
+}
+
+// changeFolders updates the workspace folders, dropping the settings cached for removed ones
+func (s *Server) changeFolders(added, removed []workspaceFolder) {
+	for _, folder := range removed {
+		path, err := uriToPath(folder.URI)
+		if err != nil {
+			continue
+		}
+		delete(s.workspaces, path)
+		for i, existing := range s.folders {
+			if existing == path {
+				s.folders = append(s.folders[:i], s.folders[i+1:]...)
+				break
+			}
+		}
+	}
+	for _, folder := range added {
+		if path, err := uriToPath(folder.URI); err == nil {
+			s.folders = append(s.folders, path)
+		}
+	}
+}
+
+// format returns the edit replacing the import block of a document, none when already grouped
+func (s *Server) format(uri string) ([]textEdit, error) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	text, ok := s.documents[uri]
+	if !ok {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		text = string(content)
+	}
+
+	config, err := s.workspaceFor(path).formatterConfig(path, s.options)
+	if err != nil {
+		return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+	}
+	fix, err := formatter.New(config).FormatImports(path, []byte(text))
+	if err != nil {
+		var parseErr *errors.ParseError
+		if stderrors.As(err, &parseErr) {
+			// Documents are often incomplete while being edited, they are left as is
+			return []textEdit{}, nil
+		}
+		return nil, &rpcError{Code: codeInternalError, Message: err.Error()}
+	}
+	if fix == nil {
+		return []textEdit{}, nil
+	}
+
+	lines := strings.Split(text, "\n")
+	endLine := strings.TrimSuffix(lines[fix.EndLine-1], "\r")
+	return []textEdit{{
+		Range: textRange{
+			Start: position{Line: fix.StartLine - 1},
+			End:   position{Line: fix.EndLine - 1, Character: len(utf16.Encode([]rune(endLine)))},
+		},
+		NewText: fix.Replacement,
+	}}, nil
+}
+
+// codeActions returns the organize imports action when requested and needed
+func (s *Server) codeActions(params *codeActionParams) ([]codeAction, error) {
+	actions := []codeAction{}
+	if !wantsOrganizeImports(params.Context.Only) {
+		return actions, nil
+	}
+
+	edits, err := s.format(params.TextDocument.URI)
+	if err != nil || len(edits) == 0 {
+		return actions, err
+	}
+	return append(actions, codeAction{
+		Title: "Group imports",
+		Kind:  codeActionKindOrganizeImports,
+		Edit:  workspaceEdit{Changes: map[string][]textEdit{params.TextDocument.URI: edits}},
+	}), nil
+}
+
+// wantsOrganizeImports checks if the requested code action kinds include organize imports
+func wantsOrganizeImports(only []string) bool {
+	if len(only) == 0 {
+		return true
+	}
+	for _, kind := range only {
+		if kind == codeActionKindSource || kind == codeActionKindOrganizeImports {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) reply(id json.RawMessage, result any, err error) error {
+	response := &message{ID: id}
+	if err != nil {
+		var rpcErr *rpcError
+		if !stderrors.As(err, &rpcErr) {
+			rpcErr = &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		response.Error = rpcErr
+	} else {
+		body, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		response.Result = body
+	}
+	return writeMessage(s.out, response)
+}
+
+func decodeParams(msg *message, params any) error {
+	if err := json.Unmarshal(msg.Params, params); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// uriToPath converts a file URI to a file path
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("%s: %s", errors.ErrMsgUnsupportedURI, uri)
+	}
+
+	path := u.Path
+	if runtime.GOOS == "windows" {
+		path = strings.TrimPrefix(path, "/") // file:///C:/dir
+	}
+	return filepath.FromSlash(path), nil
+}
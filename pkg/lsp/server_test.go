@@ -0,0 +1,220 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+)
+
+// testClient drives a server through a pair of pipes, as an editor would over stdio
+type testClient struct {
+	t      *testing.T
+	in     io.WriteCloser
+	out    *bufio.Reader
+	nextID int
+	done   chan error
+}
+
+func newTestClient(t *testing.T, options Options) *testClient {
+	clientToServer, serverIn := io.Pipe()
+	serverOut, clientFromServer := io.Pipe()
+
+	c := &testClient{t: t, in: serverIn, out: bufio.NewReader(serverOut), done: make(chan error, 1)}
+	go func() {
+		err := NewServer(clientToServer, clientFromServer, "test", options).Serve()
+		clientFromServer.Close()
+		c.done <- err
+	}()
+	return c
+}
+
+// call sends a request and decodes the result of its response
+func (c *testClient) call(method string, params, result any) *rpcError {
+	c.t.Helper()
+	c.nextID++
+	id, _ := json.Marshal(c.nextID)
+	c.send(&message{ID: id, Method: method, Params: mustMarshal(c.t, params)})
+
+	response, err := readMessage(c.out)
+	require.NoError(c.t, err)
+	require.JSONEq(c.t, string(id), string(response.ID))
+	if response.Error != nil {
+		return response.Error
+	}
+	if result != nil {
+		require.NoError(c.t, json.Unmarshal(response.Result, result))
+	}
+	return nil
+}
+
+// notify sends a notification
+func (c *testClient) notify(method string, params any) {
+	c.t.Helper()
+	c.send(&message{Method: method, Params: mustMarshal(c.t, params)})
+}
+
+func (c *testClient) send(msg *message) {
+	c.t.Helper()
+	require.NoError(c.t, writeMessage(c.in, msg))
+}
+
+func mustMarshal(t *testing.T, value any) json.RawMessage {
+	t.Helper()
+	if value == nil {
+		return nil
+	}
+	body, err := json.Marshal(value)
+	require.NoError(t, err)
+	return body
+}
+
+func fileURI(path string) string {
+	return "file://" + filepath.ToSlash(path)
+}
+
+const misgroupedSource = `package main
+
+import (
+	"github.com/acme/platform/auth"
+	"os"
+	"fmt"
+)
+
+func main() { fmt.Println(os.Args, auth.Name) }
+`
+
+func TestServer_Session(t *testing.T) {
+	req := require.New(t)
+	root := t.TempDir()
+	req.NoError(os.WriteFile(filepath.Join(root, "go.mod"), []byte("module github.com/test/project\n"), 0644))
+	req.NoError(os.WriteFile(filepath.Join(root, ".gig.yaml"), []byte("orgs: [github.com/acme]\n"), 0644))
+	path := filepath.Join(root, "main.go")
+	uri := fileURI(path)
+
+	c := newTestClient(t, Options{})
+
+	var initResult initializeResult
+	req.Nil(c.call("initialize", initializeParams{WorkspaceFolders: []workspaceFolder{{URI: fileURI(root), Name: "project"}}}, &initResult))
+	req.True(initResult.Capabilities.DocumentFormattingProvider)
+	req.Equal([]string{codeActionKindOrganizeImports}, initResult.Capabilities.CodeActionProvider.CodeActionKinds)
+	c.notify("initialized", struct{}{})
+
+	// The open document is formatted, not the file on disk
+	c.notify("textDocument/didOpen", didOpenParams{TextDocument: textDocumentItem{URI: uri, Text: misgroupedSource}})
+
+	var edits []textEdit
+	req.Nil(c.call("textDocument/formatting", documentFormattingParams{TextDocument: textDocumentIdentifier{URI: uri}}, &edits))
+	req.Equal([]textEdit{{
+		Range:   textRange{Start: position{Line: 2}, End: position{Line: 6, Character: 1}},
+		NewText: "import (\n\t\"fmt\"\n\t\"os\"\n\n\t\"github.com/acme/platform/auth\"\n)",
+	}}, edits)
+
+	var actions []codeAction
+	req.Nil(c.call("textDocument/codeAction", codeActionParams{TextDocument: textDocumentIdentifier{URI: uri}}, &actions))
+	req.Len(actions, 1)
+	req.Equal(codeActionKindOrganizeImports, actions[0].Kind)
+	req.Equal(edits, actions[0].Edit.Changes[uri])
+
+	// Other code action kinds are not provided
+	params := codeActionParams{TextDocument: textDocumentIdentifier{URI: uri}}
+	params.Context.Only = []string{"quickfix"}
+	req.Nil(c.call("textDocument/codeAction", params, &actions))
+	req.Empty(actions)
+
+	// Grouped documents need no edit
+	grouped := "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() { fmt.Println(os.Args) }\n"
+	c.notify("textDocument/didChange", didChangeParams{
+		TextDocument:   textDocumentIdentifier{URI: uri},
+		ContentChanges: []contentChange{{Text: grouped}},
+	})
+	req.Nil(c.call("textDocument/formatting", documentFormattingParams{TextDocument: textDocumentIdentifier{URI: uri}}, &edits))
+	req.Empty(edits)
+
+	// Documents with syntax errors are left as is
+	c.notify("textDocument/didChange", didChangeParams{
+		TextDocument:   textDocumentIdentifier{URI: uri},
+		ContentChanges: []contentChange{{Text: "package main\n\nimport (\n\t\"os\"\n\t\"fmt\"\n\nfunc"}},
+	})
+	req.Nil(c.call("textDocument/formatting", documentFormattingParams{TextDocument: textDocumentIdentifier{URI: uri}}, &edits))
+	req.Empty(edits)
+
+	rpcErr := c.call("textDocument/hover", struct{}{}, nil)
+	req.NotNil(rpcErr)
+	req.Equal(codeMethodNotFound, rpcErr.Code)
+
+	req.Nil(c.call("shutdown", nil, nil))
+	c.notify("exit", nil)
+	req.NoError(<-c.done)
+}
+
+func TestServer_WorkspaceCache(t *testing.T) {
+	req := require.New(t)
+	root := t.TempDir()
+	req.NoError(os.WriteFile(filepath.Join(root, "go.mod"), []byte("module github.com/test/project\n"), 0644))
+	req.NoError(os.MkdirAll(filepath.Join(root, "pkg"), 0755))
+	path := filepath.Join(root, "pkg", "a.go")
+
+	s := NewServer(nil, io.Discard, "test", Options{})
+	s.changeFolders([]workspaceFolder{{URI: fileURI(root)}}, nil)
+
+	w := s.workspaceFor(path)
+	req.Equal(root, w.root)
+	req.Same(w, s.workspaceFor(filepath.Join(root, "b.go")), "settings are cached per workspace folder")
+
+	config, err := w.formatterConfig(path, Options{})
+	req.NoError(err)
+	req.Equal("github.com/test/project", config.CurrentProject)
+	req.Contains(w.modules, filepath.Join(root, "pkg"), "modules are cached per directory")
+
+	config, err = w.formatterConfig(path, Options{CurrentProject: "github.com/other/project", Orgs: []string{"github.com/acme"}})
+	req.NoError(err)
+	req.Equal("github.com/other/project", config.CurrentProject)
+	req.Equal([]string{"github.com/acme"}, config.Orgs)
+
+	// Invalid configuration files are reported when formatting
+	req.NoError(os.WriteFile(filepath.Join(root, ".gig.yaml"), []byte("unknown: true\n"), 0644))
+	s.changeFolders(nil, []workspaceFolder{{URI: fileURI(root)}})
+	s.changeFolders([]workspaceFolder{{URI: fileURI(root)}}, nil)
+	_, err = s.workspaceFor(path).formatterConfig(path, Options{})
+	req.Error(err)
+}
+
+func TestServer_ExitWithoutShutdown(t *testing.T) {
+	c := newTestClient(t, Options{})
+	c.notify("exit", nil)
+	require.Error(t, <-c.done)
+}
+
+func TestReadMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "valid message", input: "Content-Length: 15\r\n\r\n{\"method\":\"ok\"}"},
+		{name: "missing length", input: "Content-Type: text\r\n\r\n{}", wantErr: errors.ErrMsgInvalidLength},
+		{name: "negative length", input: "Content-Length: -1\r\n\r\n{}", wantErr: errors.ErrMsgInvalidLength},
+		{name: "length above the maximum", input: "Content-Length: 9999999999\r\n\r\n{}", wantErr: errors.ErrMsgMessageTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			msg, err := readMessage(bufio.NewReader(strings.NewReader(tt.input)))
+			if tt.wantErr != "" {
+				req.ErrorContains(err, tt.wantErr)
+				return
+			}
+			req.NoError(err)
+			req.Equal("ok", msg.Method)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package lsp
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/config"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/formatter"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/utils"
+)
+
+// workspace caches the settings resolved for a workspace folder, so that the configuration
+// file and go.mod are not looked up again on every request
+type workspace struct {
+	root      string
+	config    *config.Config
+	configErr error
+	modules   map[string]string // project module of each directory
+}
+
+// workspaceFor returns the workspace of the deepest folder containing path. Files
+// outside of the workspace folders use their directory as workspace.
+func (s *Server) workspaceFor(path string) *workspace {
+	root := filepath.Dir(path)
+	longest := -1
+	for _, folder := range s.folders {
+		if isWithin(path, folder) && len(folder) > longest {
+			root, longest = folder, len(folder)
+		}
+	}
+
+	if w, ok := s.workspaces[root]; ok {
+		return w
+	}
+	w := newWorkspace(root)
+	s.workspaces[root] = w
+	return w
+}
+
+func newWorkspace(root string) *workspace {
+	w := &workspace{root: root, config: &config.Config{}, modules: make(map[string]string)}
+	found, err := config.Find(root)
+	if err != nil {
+		w.configErr = err
+	} else if found != "" {
+		w.config, w.configErr = config.Load(found)
+	}
+	return w
+}
+
+// formatterConfig resolves the formatting settings of a file, options take precedence
+// over the configuration file
+func (w *workspace) formatterConfig(path string, options Options) (formatter.FormatterConfig, error) {
+	if w.configErr != nil {
+		return formatter.FormatterConfig{}, w.configErr
+	}
+
+	config := formatter.FormatterConfig{
+		Orgs:           w.config.Orgs,
+		CurrentProject: w.config.CurrentProject,
+		Disable:        w.config.Disable,
+		Aliases:        w.config.Aliases,
+		Rules:          w.config.Rules,
+		DotImports:     w.config.DotImports,
+		BlankImports:   w.config.BlankImports,
+		Sort:           w.config.Sort,
+		Output:         io.Discard,
+	}
+	if len(options.Orgs) > 0 {
+		config.Orgs = options.Orgs
+	}
+	if options.CurrentProject != "" {
+		config.CurrentProject = options.CurrentProject
+	}
+	if config.CurrentProject == "" {
+		config.CurrentProject = w.module(path)
+	}
+	return config, nil
+}
+
+// module returns the project module of a file, cached per directory
+func (w *workspace) module(path string) string {
+	dir := filepath.Dir(path)
+	module, ok := w.modules[dir]
+	if !ok {
+		module = utils.GetProjectModule(path)
+		w.modules[dir] = module
+	}
+	return module
+}
+
+// isWithin checks if path is inside dir
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+const checkstyleVersion = "4.3"
+
+// Checkstyle XML report, as consumed by the Jenkins warnings plugin
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// checkstyleReporter collects the violations and writes a checkstyle report at the end of the run
+type checkstyleReporter struct {
+	w      io.Writer
+	report checkstyleReport
+}
+
+func newCheckstyleReporter(w io.Writer) *checkstyleReporter {
+	return &checkstyleReporter{w: w, report: checkstyleReport{Version: checkstyleVersion}}
+}
+
+// File adds the violations and the error of a file, files without any are left out
+func (r *checkstyleReporter) File(record FileRecord) error {
+	file := checkstyleFile{Name: record.Path}
+	for _, violation := range record.Violations {
+		file.Errors = append(file.Errors, checkstyleError{
+			Line:     violation.Line,
+			Column:   violation.Column,
+			Severity: "error",
+			Message:  violation.Message,
+			Source:   toolName + "." + violation.Code,
+		})
+	}
+	if record.Error != nil {
+		file.Errors = append(file.Errors, checkstyleError{
+			Line:     record.Error.Line,
+			Column:   record.Error.Column,
+			Severity: "error",
+			Message:  record.Error.Message,
+			Source:   toolName + "." + record.Error.Kind,
+		})
+	}
+
+	if len(file.Errors) > 0 {
+		r.report.Files = append(r.report.Files, file)
+	}
+	return nil
+}
+
+func (r *checkstyleReporter) Close(Summary) error {
+	if _, err := io.WriteString(r.w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(r.w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(r.report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(r.w, "\n")
+	return err
+}
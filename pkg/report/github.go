@@ -0,0 +1,59 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// githubReporter writes GitHub Actions workflow commands, so that violations are
+// shown as annotations on the lines of the pull request
+type githubReporter struct {
+	w io.Writer
+}
+
+func newGitHubReporter(w io.Writer) *githubReporter {
+	return &githubReporter{w: w}
+}
+
+// File writes one error annotation per violation, pointing at the out of place import
+func (r *githubReporter) File(record FileRecord) error {
+	for _, violation := range record.Violations {
+		if err := r.annotate(record.Path, violation.Line, violation.Column, violation.Code+" "+violation.Rule, violation.Message); err != nil {
+			return err
+		}
+	}
+	if record.Error != nil {
+		return r.annotate(record.Path, record.Error.Line, record.Error.Column, toolName+" "+record.Error.Kind+" error", record.Error.Message)
+	}
+	return nil
+}
+
+func (r *githubReporter) Close(Summary) error {
+	return nil
+}
+
+// annotate writes an ::error workflow command, omitting the position when unknown
+func (r *githubReporter) annotate(path string, line, column int, title, message string) error {
+	properties := []string{"file=" + escapeGitHubProperty(path)}
+	if line > 0 {
+		properties = append(properties, fmt.Sprintf("line=%d", line))
+		if column > 0 {
+			properties = append(properties, fmt.Sprintf("col=%d", column))
+		}
+	}
+	properties = append(properties, "title="+escapeGitHubProperty(title))
+
+	_, err := fmt.Fprintf(r.w, "::error %s::%s\n", strings.Join(properties, ","), escapeGitHubData(message))
+	return err
+}
+
+// escapeGitHubData escapes the message of a workflow command
+func escapeGitHubData(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+// escapeGitHubProperty escapes a property value of a workflow command
+func escapeGitHubProperty(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(value)
+}
@@ -0,0 +1,50 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// jsonDocument is the document written by the JSON reporter
+type jsonDocument struct {
+	Files   []FileRecord `json:"files"`
+	Summary Summary      `json:"summary"`
+}
+
+// jsonReporter collects the records and writes a single JSON document at the end of the run
+type jsonReporter struct {
+	w     io.Writer
+	files []FileRecord
+}
+
+func newJSONReporter(w io.Writer) *jsonReporter {
+	return &jsonReporter{w: w, files: []FileRecord{}}
+}
+
+func (r *jsonReporter) File(record FileRecord) error {
+	r.files = append(r.files, record)
+	return nil
+}
+
+func (r *jsonReporter) Close(summary Summary) error {
+	encoder := json.NewEncoder(r.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(jsonDocument{Files: r.files, Summary: summary})
+}
+
+// ndjsonReporter streams one JSON record per line as files are processed
+type ndjsonReporter struct {
+	encoder *json.Encoder
+}
+
+func newNDJSONReporter(w io.Writer) *ndjsonReporter {
+	return &ndjsonReporter{encoder: json.NewEncoder(w)}
+}
+
+func (r *ndjsonReporter) File(record FileRecord) error {
+	return r.encoder.Encode(record)
+}
+
+func (r *ndjsonReporter) Close(Summary) error {
+	return nil
+}
@@ -0,0 +1,255 @@
+package report
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+)
+
+// Format is the output format of a run
+type Format string
+
+const (
+	FormatText       Format = "text"       // human readable progress lines
+	FormatJSON       Format = "json"       // single JSON document written at the end of the run
+	FormatNDJSON     Format = "ndjson"     // one JSON record per line, streamed as files are processed
+	FormatSARIF      Format = "sarif"      // SARIF 2.1.0 log for code scanning, check mode only
+	FormatGitHub     Format = "github"     // GitHub Actions workflow commands, check mode only
+	FormatCheckstyle Format = "checkstyle" // checkstyle XML report, check mode only
+)
+
+// Formats lists the supported output formats
+var Formats = []Format{FormatText, FormatJSON, FormatNDJSON, FormatSARIF, FormatGitHub, FormatCheckstyle}
+
+// RequiresCheck checks if the format only reports violations, which are only found in check mode
+func (f Format) RequiresCheck() bool {
+	return f == FormatSARIF || f == FormatGitHub || f == FormatCheckstyle
+}
+
+// Violation rules reported in check mode
+const (
+	RuleWrongGroup        = "wrong-group"        // an import is placed in the block of another group
+	RuleMissingBlankLine  = "missing-blank-line" // two groups are not separated by a blank line
+	RuleWrongOrder        = "wrong-order"        // groups are not in the expected order
+	RuleUnsorted          = "unsorted"           // imports are not sorted within their group
+	RuleDuplicateImport   = "duplicate-import"   // the same path is imported more than once
+	RuleSplitGroup        = "split-group"        // the imports of a group are split across several blocks
+	RuleUnusedImport      = "unused-import"      // an import is not referenced, reported with --remove-unused
+	RuleMissingImport     = "missing-import"     // a qualifier is not imported, reported with --add-missing
+	RuleRedundantAlias    = "redundant-alias"    // an alias is the name of the package, reported with --remove-aliases
+	RuleRequiredAlias     = "required-alias"     // an import is not named after the alias required by the configuration
+	RuleDeniedImport      = "denied-import"      // an import is denied by the rules of the configuration
+	RuleRewrittenImport   = "rewritten-import"   // an import path is rewritten, reported by gig rewrite
+	RuleDeprecatedSymbol  = "deprecated-symbol"  // a deprecated symbol of the standard library is used, reported with --migrate-deprecated
+	RuleDotImport         = "dot-import"         // a dot import is not allowed by the dot-imports policy
+	RuleBlankImport       = "blank-import"       // a blank import is not allowed by the blank-imports policy
+	RuleInconsistentAlias = "inconsistent-alias" // an import is named differently than in most files, reported with --consistent-aliases
+)
+
+// Rule describes a violation rule. Codes are stable across releases so that
+// teams can rely on them to suppress specific violations.
+type Rule struct {
+	Code        string
+	ID          string
+	Description string
+}
+
+// Rules lists the violation rules, codes are never reused
+var Rules = []Rule{
+	{"GIG001", RuleWrongGroup, "An import is placed in the block of another group"},
+	{"GIG002", RuleMissingBlankLine, "Import groups are not separated by a blank line"},
+	{"GIG003", RuleWrongOrder, "Import groups are not in the expected order"},
+	{"GIG004", RuleUnsorted, "Imports are not sorted within their group"},
+	{"GIG005", RuleDuplicateImport, "The same package is imported more than once"},
+	{"GIG006", RuleSplitGroup, "The imports of a group are split across several blocks"},
+	{"GIG007", RuleUnusedImport, "An import is not used by the file"},
+	{"GIG008", RuleMissingImport, "A package qualifier is used without being imported"},
+	{"GIG009", RuleRedundantAlias, "An import is aliased to the name of its package"},
+	{"GIG010", RuleRequiredAlias, "An import is not named after the alias required by the configuration"},
+	{"GIG011", RuleDeniedImport, "An import is denied by the rules of the configuration"},
+	{"GIG012", RuleRewrittenImport, "An import path is rewritten to another one"},
+	{"GIG013", RuleDeprecatedSymbol, "A deprecated symbol of the standard library is used"},
+	{"GIG014", RuleDotImport, "A dot import is not allowed by the configuration"},
+	{"GIG015", RuleBlankImport, "A blank import is not allowed by the configuration"},
+	{"GIG016", RuleInconsistentAlias, "An import is named differently than in most files of its package or module"},
+}
+
+// LookupRule finds a rule by code or ID, case-insensitively
+func LookupRule(name string) (Rule, bool) {
+	for _, rule := range Rules {
+		if strings.EqualFold(rule.Code, name) || strings.EqualFold(rule.ID, name) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// RuleCode returns the stable code of a rule ID
+func RuleCode(id string) string {
+	rule, _ := LookupRule(id)
+	return rule.Code
+}
+
+// Status describes the outcome of processing a single file
+type Status string
+
+const (
+	StatusChanged   Status = "changed"   // imports were regrouped
+	StatusUnchanged Status = "unchanged" // imports were already grouped
+	StatusSkipped   Status = "skipped"   // file was left untouched, e.g. outside the requested line ranges
+	StatusError     Status = "error"     // file failed to process
+)
+
+// Error kinds reported in ErrorRecord
+const (
+	ErrorKindRead   = "read"
+	ErrorKindWrite  = "write"
+	ErrorKindParse  = "parse"
+	ErrorKindConfig = "config"
+	ErrorKindOther  = "other"
+)
+
+// FileRecord describes the outcome of processing a single file
+type FileRecord struct {
+	Path   string         `json:"path"`
+	Status Status         `json:"status"`
+	Module string         `json:"module,omitempty"` // resolved project module
+	Groups map[string]int `json:"groups,omitempty"` // number of imports per group
+	Error  *ErrorRecord   `json:"error,omitempty"`
+
+	// Check mode results
+	Violations []Violation `json:"violations,omitempty"`
+	Fix        *Fix        `json:"fix,omitempty"`
+
+	// Issues that could not be fixed, e.g. a missing import matching several packages
+	Warnings []Violation `json:"warnings,omitempty"`
+}
+
+// Violation describes why the imports of a file are not compliant
+type Violation struct {
+	Code    string `json:"code"` // stable code of the rule, e.g. GIG001
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column,omitempty"`
+}
+
+// Fix describes the replacement of the import block of a file
+type Fix struct {
+	StartLine   int    `json:"start_line"`  // first line of the import block
+	EndLine     int    `json:"end_line"`    // last line of the import block
+	Replacement string `json:"replacement"` // grouped import block replacing the lines
+}
+
+// ErrorRecord describes the error of a file that failed to process
+type ErrorRecord struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+}
+
+// Options controls the output of the reporters
+type Options struct {
+	InPlace bool // files are modified in place
+	Check   bool // files are checked for violations instead of being formatted
+}
+
+// Summary aggregates the statuses of all the files of a run
+type Summary struct {
+	Total     int `json:"total"`
+	Changed   int `json:"changed"`
+	Unchanged int `json:"unchanged"`
+	Skipped   int `json:"skipped"`
+	Errors    int `json:"errors"`
+}
+
+// Add counts a file record in the summary
+func (s *Summary) Add(record FileRecord) {
+	s.Total++
+	switch record.Status {
+	case StatusChanged:
+		s.Changed++
+	case StatusUnchanged:
+		s.Unchanged++
+	case StatusSkipped:
+		s.Skipped++
+	case StatusError:
+		s.Errors++
+	}
+}
+
+// Reporter receives the records of a run and writes them in a specific format
+type Reporter interface {
+	// File reports the outcome of processing a single file
+	File(record FileRecord) error
+	// Close reports the summary of the run, it is called once at the end of the run
+	Close(summary Summary) error
+}
+
+// ParseFormat validates an output format name
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == name {
+			return format, nil
+		}
+	}
+
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return "", &errors.ConfigError{
+		Key: "format",
+		Err: fmt.Errorf("%s %q, expected one of: %s", errors.ErrMsgUnknownFormat, name, strings.Join(names, ", ")),
+	}
+}
+
+// New creates a reporter writing the given format to w. Unknown formats fall back to text.
+func New(format Format, w io.Writer, opts Options) Reporter {
+	switch format {
+	case FormatJSON:
+		return newJSONReporter(w)
+	case FormatNDJSON:
+		return newNDJSONReporter(w)
+	case FormatSARIF:
+		return newSARIFReporter(w)
+	case FormatGitHub:
+		return newGitHubReporter(w)
+	case FormatCheckstyle:
+		return newCheckstyleReporter(w)
+	default:
+		return newTextReporter(w, opts)
+	}
+}
+
+// NewErrorRecord describes an error, including its kind and position when available
+func NewErrorRecord(err error) *ErrorRecord {
+	record := &ErrorRecord{
+		Kind:    ErrorKindOther,
+		Message: err.Error(),
+	}
+
+	var parseErr *errors.ParseError
+	var readErr *errors.ReadError
+	var writeErr *errors.WriteError
+	var configErr *errors.ConfigError
+	switch {
+	case stderrors.As(err, &parseErr):
+		record.Kind = ErrorKindParse
+		record.Line = parseErr.Pos.Line
+		record.Column = parseErr.Pos.Column
+	case stderrors.As(err, &readErr):
+		record.Kind = ErrorKindRead
+	case stderrors.As(err, &writeErr):
+		record.Kind = ErrorKindWrite
+	case stderrors.As(err, &configErr):
+		record.Kind = ErrorKindConfig
+		record.Line = configErr.Pos.Line
+		record.Column = configErr.Pos.Column
+	}
+	return record
+}
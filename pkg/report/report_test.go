@@ -0,0 +1,272 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+)
+
+func TestParseFormat(t *testing.T) {
+	req := require.New(t)
+
+	for _, name := range []string{"text", "json", "ndjson", "sarif", "github", "checkstyle"} {
+		format, err := ParseFormat(name)
+		req.NoError(err, "ParseFormat(%q)", name)
+		req.Equal(Format(name), format)
+	}
+
+	_, err := ParseFormat("xml")
+	req.Error(err)
+	var configErr *errors.ConfigError
+	req.True(stderrors.As(err, &configErr), "unknown format should be a ConfigError")
+	req.Equal("format", configErr.Key)
+}
+
+func TestNewErrorRecord(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantKind   string
+		wantLine   int
+		wantColumn int
+	}{
+		{
+			name:       "parse error",
+			err:        &errors.ParseError{Path: "a.go", Pos: token.Position{Filename: "a.go", Line: 4, Column: 2}, Err: stderrors.New("syntax")},
+			wantKind:   ErrorKindParse,
+			wantLine:   4,
+			wantColumn: 2,
+		},
+		{
+			name:     "wrapped read error",
+			err:      fmt.Errorf("wrapped: %w", &errors.ReadError{Path: "a.go", Err: stderrors.New("denied")}),
+			wantKind: ErrorKindRead,
+		},
+		{
+			name:     "write error",
+			err:      &errors.WriteError{Path: "a.go", Err: stderrors.New("read-only")},
+			wantKind: ErrorKindWrite,
+		},
+		{
+			name:     "other error",
+			err:      stderrors.New("boom"),
+			wantKind: ErrorKindOther,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			record := NewErrorRecord(tt.err)
+			req.Equal(tt.wantKind, record.Kind)
+			req.Equal(tt.err.Error(), record.Message)
+			req.Equal(tt.wantLine, record.Line)
+			req.Equal(tt.wantColumn, record.Column)
+		})
+	}
+}
+
+var testRecords = []FileRecord{
+	{Path: "a.go", Status: StatusChanged, Module: "github.com/test/project", Groups: map[string]int{"std": 2, "third-party": 1}},
+	{Path: "b.go", Status: StatusSkipped, Module: "github.com/test/project"},
+	{Path: "c.go", Status: StatusError, Error: &ErrorRecord{Kind: ErrorKindParse, Message: "failed to parse file", Line: 3, Column: 1}},
+}
+
+func writeRecords(t *testing.T, reporter Reporter) {
+	t.Helper()
+	var summary Summary
+	for _, record := range testRecords {
+		summary.Add(record)
+		require.NoError(t, reporter.File(record))
+	}
+	require.NoError(t, reporter.Close(summary))
+}
+
+func TestTextReporter(t *testing.T) {
+	req := require.New(t)
+	var buf bytes.Buffer
+	writeRecords(t, New(FormatText, &buf, Options{InPlace: true}))
+
+	req.Equal("Processed: a.go\n"+
+		"Untouched: b.go\n"+
+		"Error processing c.go: failed to parse file\n"+
+		"\nProcessed 2 files successfully, 1 files had errors\n", buf.String())
+}
+
+func TestJSONReporter(t *testing.T) {
+	req := require.New(t)
+	var buf bytes.Buffer
+	writeRecords(t, New(FormatJSON, &buf, Options{}))
+
+	var document jsonDocument
+	req.NoError(json.Unmarshal(buf.Bytes(), &document))
+	req.Equal(testRecords, document.Files)
+	req.Equal(Summary{Total: 3, Changed: 1, Skipped: 1, Errors: 1}, document.Summary)
+
+	// Empty runs still produce a document
+	buf.Reset()
+	req.NoError(New(FormatJSON, &buf, Options{}).Close(Summary{}))
+	req.JSONEq(`{"files": [], "summary": {"total": 0, "changed": 0, "unchanged": 0, "skipped": 0, "errors": 0}}`, buf.String())
+}
+
+func TestNDJSONReporter(t *testing.T) {
+	req := require.New(t)
+	var buf bytes.Buffer
+	writeRecords(t, New(FormatNDJSON, &buf, Options{}))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	req.Len(lines, len(testRecords))
+	for i, line := range lines {
+		var record FileRecord
+		req.NoError(json.Unmarshal([]byte(line), &record), "line %d", i)
+		req.Equal(testRecords[i], record)
+	}
+	req.JSONEq(`{"path": "c.go", "status": "error", "error": {"kind": "parse", "message": "failed to parse file", "line": 3, "column": 1}}`, lines[2])
+}
+
+var checkRecord = FileRecord{
+	Path:   "pkg/a.go",
+	Status: StatusChanged,
+	Violations: []Violation{
+		{Code: "GIG004", Rule: RuleUnsorted, Message: "imports are not sorted within the std group", Line: 5, Column: 2},
+		{Code: "GIG002", Rule: RuleMissingBlankLine, Message: "missing blank line between std and third-party imports", Line: 6, Column: 2},
+	},
+	Fix: &Fix{StartLine: 3, EndLine: 7, Replacement: "import (\n\t\"fmt\"\n\t\"os\"\n\n\t\"github.com/external/lib\"\n)"},
+}
+
+func TestTextReporter_Check(t *testing.T) {
+	req := require.New(t)
+	var buf bytes.Buffer
+	reporter := New(FormatText, &buf, Options{Check: true})
+
+	var summary Summary
+	for _, record := range []FileRecord{checkRecord, {Path: "pkg/b.go", Status: StatusUnchanged}} {
+		summary.Add(record)
+		req.NoError(reporter.File(record))
+	}
+	req.NoError(reporter.Close(summary))
+
+	req.Equal("pkg/a.go:5:2: GIG004 imports are not sorted within the std group (unsorted)\n"+
+		"pkg/a.go:6:2: GIG002 missing blank line between std and third-party imports (missing-blank-line)\n"+
+		"\nProcessed 2 files successfully, 1 files have misgrouped imports\n", buf.String())
+}
+
+func TestSARIFReporter(t *testing.T) {
+	req := require.New(t)
+	var buf bytes.Buffer
+	reporter := New(FormatSARIF, &buf, Options{Check: true})
+	req.NoError(reporter.File(checkRecord))
+	req.NoError(reporter.File(FileRecord{Path: "pkg/b.go", Status: StatusUnchanged}))
+	req.NoError(reporter.Close(Summary{}))
+
+	var log sarifLog
+	req.NoError(json.Unmarshal(buf.Bytes(), &log))
+	req.Equal("2.1.0", log.Version)
+	req.Len(log.Runs, 1)
+	req.Len(log.Runs[0].Tool.Driver.Rules, len(Rules))
+
+	// Each violation of the misgrouped file is a result with its own rule and region
+	results := log.Runs[0].Results
+	req.Len(results, 2)
+	for i, violation := range checkRecord.Violations {
+		req.Equal(violation.Code, results[i].RuleID)
+		req.Equal("warning", results[i].Level)
+		req.Equal(violation.Message, results[i].Message.Text)
+		req.Equal("pkg/a.go", results[i].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		req.Equal(sarifRegion{StartLine: violation.Line, StartColumn: violation.Column}, results[i].Locations[0].PhysicalLocation.Region)
+	}
+
+	// The fix replacing the import block is attached once
+	req.Len(results[0].Fixes, 1)
+	req.Empty(results[1].Fixes)
+	replacement := results[0].Fixes[0].ArtifactChanges[0].Replacements[0]
+	req.Equal(sarifRegion{StartLine: 3, EndLine: 7}, replacement.DeletedRegion)
+	req.Equal(checkRecord.Fix.Replacement, replacement.InsertedContent.Text)
+
+	// Runs without violations still produce a valid log
+	buf.Reset()
+	req.NoError(New(FormatSARIF, &buf, Options{Check: true}).Close(Summary{}))
+	req.Contains(buf.String(), `"results": []`)
+}
+
+func TestSARIFURI(t *testing.T) {
+	req := require.New(t)
+	req.Equal("pkg/a.go", sarifURI("./pkg/a.go"))
+	req.Equal("file:///src/my%20project/a.go", sarifURI("/src/my project/a.go"))
+}
+
+func TestFormat_RequiresCheck(t *testing.T) {
+	req := require.New(t)
+	for _, format := range []Format{FormatText, FormatJSON, FormatNDJSON} {
+		req.False(format.RequiresCheck(), "%s", format)
+	}
+	for _, format := range []Format{FormatSARIF, FormatGitHub, FormatCheckstyle} {
+		req.True(format.RequiresCheck(), "%s", format)
+	}
+}
+
+func TestGitHubReporter(t *testing.T) {
+	req := require.New(t)
+	var buf bytes.Buffer
+	reporter := New(FormatGitHub, &buf, Options{Check: true})
+	req.NoError(reporter.File(checkRecord))
+	req.NoError(reporter.File(FileRecord{Path: "pkg/b.go", Status: StatusUnchanged}))
+	req.NoError(reporter.File(FileRecord{Path: "pkg/c,d.go", Status: StatusError, Error: &ErrorRecord{Kind: ErrorKindRead, Message: "100% denied"}}))
+	req.NoError(reporter.Close(Summary{}))
+
+	req.Equal("::error file=pkg/a.go,line=5,col=2,title=GIG004 unsorted::imports are not sorted within the std group\n"+
+		"::error file=pkg/a.go,line=6,col=2,title=GIG002 missing-blank-line::missing blank line between std and third-party imports\n"+
+		"::error file=pkg/c%2Cd.go,title=gig read error::100%25 denied\n", buf.String())
+}
+
+func TestCheckstyleReporter(t *testing.T) {
+	req := require.New(t)
+	var buf bytes.Buffer
+	reporter := New(FormatCheckstyle, &buf, Options{Check: true})
+	req.NoError(reporter.File(checkRecord))
+	req.NoError(reporter.File(FileRecord{Path: "pkg/b.go", Status: StatusUnchanged}))
+	req.NoError(reporter.Close(Summary{}))
+
+	req.Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3">
+  <file name="pkg/a.go">
+    <error line="5" column="2" severity="error" message="imports are not sorted within the std group" source="gig.GIG004"></error>
+    <error line="6" column="2" severity="error" message="missing blank line between std and third-party imports" source="gig.GIG002"></error>
+  </file>
+</checkstyle>
+`, buf.String())
+}
+
+func TestLookupRule(t *testing.T) {
+	req := require.New(t)
+
+	rule, ok := LookupRule("GIG002")
+	req.True(ok)
+	req.Equal(RuleMissingBlankLine, rule.ID)
+
+	rule, ok = LookupRule("unsorted")
+	req.True(ok)
+	req.Equal("GIG004", rule.Code)
+
+	_, ok = LookupRule("gig004")
+	req.True(ok, "codes are case-insensitive")
+
+	_, ok = LookupRule("GIG999")
+	req.False(ok)
+
+	// Codes are stable and never reused
+	codes := make(map[string]bool)
+	for _, rule := range Rules {
+		req.False(codes[rule.Code], "duplicate code %s", rule.Code)
+		codes[rule.Code] = true
+	}
+	req.Equal("GIG001", RuleCode(RuleWrongGroup))
+}
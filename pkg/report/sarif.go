@@ -0,0 +1,170 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "gig"
+	toolURI      = "https://github.com/siyuan-infoblox/go-imports-group"
+)
+
+// SARIF 2.1.0 log, limited to the properties used by gig
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+	Fixes     []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion  `json:"deletedRegion"`
+	InsertedContent sarifMessage `json:"insertedContent"`
+}
+
+// sarifReporter collects the violations and writes a SARIF log at the end of the run
+type sarifReporter struct {
+	w       io.Writer
+	results []sarifResult
+}
+
+func newSARIFReporter(w io.Writer) *sarifReporter {
+	return &sarifReporter{w: w, results: []sarifResult{}}
+}
+
+// File adds one result per violation, located at the import it concerns. The fix
+// replacing the import block is attached to the first result of the file only, so that
+// it is applied once
+func (r *sarifReporter) File(record FileRecord) error {
+	uri := sarifURI(record.Path)
+	for i, violation := range record.Violations {
+		result := sarifResult{
+			RuleID:  violation.Code,
+			Level:   "warning",
+			Message: sarifMessage{Text: violation.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: uri},
+					Region:           sarifRegion{StartLine: violation.Line, StartColumn: violation.Column},
+				},
+			}},
+		}
+		if i == 0 && record.Fix != nil {
+			result.Fixes = []sarifFix{{
+				Description: sarifMessage{Text: "Group and sort imports"},
+				ArtifactChanges: []sarifArtifactChange{{
+					ArtifactLocation: sarifArtifactLocation{URI: uri},
+					Replacements: []sarifReplacement{{
+						DeletedRegion:   sarifRegion{StartLine: record.Fix.StartLine, EndLine: record.Fix.EndLine},
+						InsertedContent: sarifMessage{Text: record.Fix.Replacement},
+					}},
+				}},
+			}}
+		}
+		r.results = append(r.results, result)
+	}
+	return nil
+}
+
+func (r *sarifReporter) Close(Summary) error {
+	rules := make([]sarifRule, len(Rules))
+	for i, rule := range Rules {
+		rules[i] = sarifRule{ID: rule.Code, Name: rule.ID, ShortDescription: sarifMessage{Text: rule.Description}}
+	}
+
+	log := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           toolName,
+				InformationURI: toolURI,
+				Rules:          rules,
+			}},
+			Results: r.results,
+		}},
+	}
+
+	encoder := json.NewEncoder(r.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+// sarifURI converts a file path to a URI, relative paths are kept relative to the source root
+func sarifURI(path string) string {
+	slashPath := filepath.ToSlash(path)
+	if filepath.IsAbs(path) {
+		if !strings.HasPrefix(slashPath, "/") {
+			slashPath = "/" + slashPath // Windows drive letters
+		}
+		return (&url.URL{Scheme: "file", Path: slashPath}).String()
+	}
+	return (&url.URL{Path: strings.TrimPrefix(slashPath, "./")}).String()
+}
@@ -0,0 +1,58 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+)
+
+// textReporter writes human readable progress lines
+type textReporter struct {
+	w    io.Writer
+	opts Options
+}
+
+func newTextReporter(w io.Writer, opts Options) *textReporter {
+	return &textReporter{w: w, opts: opts}
+}
+
+func (r *textReporter) File(record FileRecord) error {
+	for _, violation := range slices.Concat(record.Violations, record.Warnings) {
+		if _, err := fmt.Fprintf(r.w, errors.InfoMsgViolation+"\n", record.Path, violation.Line, violation.Column, violation.Code, violation.Message, violation.Rule); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	switch record.Status {
+	case StatusError:
+		_, err = fmt.Fprintf(r.w, errors.InfoMsgErrorProcessing+"\n", record.Path, record.Error.Message)
+	case StatusSkipped:
+		_, err = fmt.Fprintf(r.w, errors.InfoMsgUntouchedFiles+"\n", record.Path)
+	default:
+		if r.opts.InPlace {
+			_, err = fmt.Fprintf(r.w, errors.InfoMsgProcessedFiles+"\n", record.Path)
+		}
+	}
+	return err
+}
+
+func (r *textReporter) Close(summary Summary) error {
+	if _, err := fmt.Fprintf(r.w, errors.InfoMsgProcessedCount, summary.Total-summary.Errors); err != nil {
+		return err
+	}
+	if summary.Errors > 0 {
+		if _, err := fmt.Fprintf(r.w, errors.InfoMsgErrorCount, summary.Errors); err != nil {
+			return err
+		}
+	}
+	if r.opts.Check && summary.Changed > 0 {
+		if _, err := fmt.Fprintf(r.w, errors.InfoMsgViolationCount, summary.Changed); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(r.w)
+	return err
+}
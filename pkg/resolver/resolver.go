@@ -0,0 +1,441 @@
+// Package resolver finds the packages providing the qualifiers used by a Go file from
+// on-disk sources only: the standard library, the packages of the current module and
+// the modules it requires, as found in GOMODCACHE. It never accesses the network.
+package resolver
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/std"
+	"github.com/siyuan-infoblox/go-imports-group/pkg/utils"
+)
+
+// pkg is a package found on disk
+type pkg struct {
+	path string // import path
+	name string // name from the package clause
+	dir  string // directory of the sources
+}
+
+// moduleIndex lists the packages visible from a module: its own and the ones of its requirements
+type moduleIndex struct {
+	path      string          // module path
+	goVersion string          // go directive, e.g. go1.22.0
+	packages  []pkg           // packages of the module and its requirements
+	byPath    map[string]*pkg // packages by import path
+}
+
+// Resolver resolves qualifiers to import paths. Lookups are cached, a resolver is meant
+// to be shared by the files of a run.
+type Resolver struct {
+	goroot   string
+	modCache string
+
+	mu      sync.Mutex
+	modules map[string]*moduleIndex    // by go.mod path
+	exports map[string]map[string]bool // exported names by directory
+	std     []pkg
+}
+
+// New creates a resolver looking up the standard library in GOROOT and the
+// dependencies in GOMODCACHE
+func New() *Resolver {
+	return &Resolver{
+		goroot:   build.Default.GOROOT,
+		modCache: modCacheDir(),
+		modules:  make(map[string]*moduleIndex),
+		exports:  make(map[string]map[string]bool),
+	}
+}
+
+// modCacheDir returns the module cache directory, $GOMODCACHE or $GOPATH/pkg/mod
+func modCacheDir() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	gopath := filepath.SplitList(build.Default.GOPATH)
+	if len(gopath) == 0 {
+		return ""
+	}
+	return filepath.Join(gopath[0], "pkg", "mod")
+}
+
+// PackageName returns the name of the package imported by importPath from a file,
+// read from its package clause when its sources are on disk, assumed from the path otherwise
+func (r *Resolver) PackageName(filePath, importPath string) string {
+	if std.IsStandardPackage(importPath) {
+		return AssumedName(importPath)
+	}
+	if p := r.module(filePath).byPath[importPath]; p != nil {
+		return p.name
+	}
+	return AssumedName(importPath)
+}
+
+// GoVersion returns the go directive of the module of a file, e.g. go1.22.0, empty
+// outside of modules or when the go.mod file has none
+func (r *Resolver) GoVersion(filePath string) string {
+	return r.module(filePath).goVersion
+}
+
+// Candidates returns the import paths of the packages visible from a file that are
+// named name and export all the symbols, sorted
+func (r *Resolver) Candidates(filePath, name string, symbols []string) []string {
+	index := r.module(filePath)
+	importer := importerPath(index, filePath)
+
+	var candidates []string
+	for _, p := range r.stdPackages() {
+		if p.name == name && r.exportsAll(p.dir, symbols) && isVisible(p.path, importer) {
+			candidates = append(candidates, p.path)
+		}
+	}
+	for _, p := range index.packages {
+		if p.name == name && p.path != importer && isVisible(p.path, importer) && r.exportsAll(p.dir, symbols) {
+			candidates = append(candidates, p.path)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// Exports checks if the package imported by importPath from a file exports all the symbols.
+// Packages that are not found on disk are assumed to export them.
+func (r *Resolver) Exports(filePath, importPath string, symbols []string) bool {
+	if dir := r.packageDir(filePath, importPath); dir != "" {
+		return r.exportsAll(dir, symbols)
+	}
+	return true
+}
+
+// ExportedNames returns the exported top-level names of the package imported by
+// importPath from a file, nil when its sources are not on disk
+func (r *Resolver) ExportedNames(filePath, importPath string) map[string]bool {
+	if dir := r.packageDir(filePath, importPath); dir != "" {
+		return r.dirExports(dir)
+	}
+	return nil
+}
+
+// packageDir returns the directory of the sources of the package imported by importPath
+// from a file, empty when it is not found
+func (r *Resolver) packageDir(filePath, importPath string) string {
+	if std.IsStandardPackage(importPath) {
+		return filepath.Join(r.goroot, "src", filepath.FromSlash(importPath))
+	}
+	if p := r.module(filePath).byPath[importPath]; p != nil {
+		return p.dir
+	}
+	return ""
+}
+
+// stdPackages lists the importable packages of the standard library
+func (r *Resolver) stdPackages() []pkg {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.std == nil {
+		for importPath := range std.StandardPackages {
+			r.std = append(r.std, pkg{
+				path: importPath,
+				name: AssumedName(importPath),
+				dir:  filepath.Join(r.goroot, "src", filepath.FromSlash(importPath)),
+			})
+		}
+		sort.Slice(r.std, func(i, j int) bool { return r.std[i].path < r.std[j].path })
+	}
+	return r.std
+}
+
+// exportsAll checks if the package in a directory exports all the symbols. Directories
+// that cannot be read, e.g. without GOROOT sources, are assumed to export them.
+func (r *Resolver) exportsAll(dir string, symbols []string) bool {
+	exports := r.dirExports(dir)
+	if exports == nil {
+		return true
+	}
+	for _, symbol := range symbols {
+		if !exports[symbol] {
+			return false
+		}
+	}
+	return true
+}
+
+// dirExports returns the exported names of the package in a directory, read once
+func (r *Resolver) dirExports(dir string) map[string]bool {
+	r.mu.Lock()
+	exports, ok := r.exports[dir]
+	r.mu.Unlock()
+	if !ok {
+		exports = readExports(dir)
+		r.mu.Lock()
+		r.exports[dir] = exports
+		r.mu.Unlock()
+	}
+	return exports
+}
+
+// readExports collects the exported top-level names of the package in a directory,
+// nil when it has no readable sources
+func readExports(dir string) map[string]bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	fileSet := token.NewFileSet()
+	var exports map[string]bool
+	for _, entry := range entries {
+		if !isPackageFile(entry) {
+			continue
+		}
+		file, err := parser.ParseFile(fileSet, filepath.Join(dir, entry.Name()), nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		if exports == nil {
+			exports = make(map[string]bool)
+		}
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil && decl.Name.IsExported() {
+					exports[decl.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						if spec.Name.IsExported() {
+							exports[spec.Name.Name] = true
+						}
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							if name.IsExported() {
+								exports[name.Name] = true
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return exports
+}
+
+// module returns the index of the module containing a file, an empty one outside of modules
+func (r *Resolver) module(filePath string) *moduleIndex {
+	goModPath := utils.FindGoMod(filePath)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if index, ok := r.modules[goModPath]; ok {
+		return index
+	}
+	index := &moduleIndex{byPath: make(map[string]*pkg)}
+	r.modules[goModPath] = index
+	if goModPath == "" {
+		return index
+	}
+
+	content, err := os.ReadFile(goModPath)
+	if err != nil {
+		return index
+	}
+	modFile, err := modfile.Parse(goModPath, content, nil)
+	if err != nil || modFile.Module == nil {
+		return index
+	}
+	root := filepath.Dir(goModPath)
+	index.path = modFile.Module.Mod.Path
+	if modFile.Go != nil {
+		index.goVersion = "go" + modFile.Go.Version
+	}
+	index.packages = modulePackages(root, index.path)
+
+	replacements := make(map[string]module.Version)
+	for _, replace := range modFile.Replace {
+		replacements[replace.Old.Path] = replace.New
+	}
+	for _, require := range modFile.Require {
+		dir := ""
+		if replace, ok := replacements[require.Mod.Path]; ok {
+			if replace.Version == "" {
+				// Local directory replacement
+				dir = replace.Path
+				if !filepath.IsAbs(dir) {
+					dir = filepath.Join(root, dir)
+				}
+			} else {
+				dir = r.modCachePath(replace)
+			}
+		} else {
+			dir = r.modCachePath(require.Mod)
+		}
+		if dir != "" {
+			index.packages = append(index.packages, modulePackages(dir, require.Mod.Path)...)
+		}
+	}
+
+	for i := range index.packages {
+		p := &index.packages[i]
+		if _, ok := index.byPath[p.path]; !ok {
+			index.byPath[p.path] = p
+		}
+	}
+	return index
+}
+
+// modCachePath returns the directory of a module version in the module cache
+func (r *Resolver) modCachePath(version module.Version) string {
+	if r.modCache == "" {
+		return ""
+	}
+	escapedPath, err := module.EscapePath(version.Path)
+	if err != nil {
+		return ""
+	}
+	escapedVersion, err := module.EscapeVersion(version.Version)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(r.modCache, filepath.FromSlash(escapedPath)+"@"+escapedVersion)
+}
+
+// modulePackages walks the directory of a module and lists its packages, leaving out
+// main packages, nested modules and the directories ignored by the go command
+func modulePackages(root, modulePath string) []pkg {
+	var packages []pkg
+	fileSet := token.NewFileSet()
+	filepath.WalkDir(root, func(dir string, entry os.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil
+		}
+		if dir != root {
+			name := entry.Name()
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+
+		name := packageName(fileSet, dir)
+		if name == "" || name == "main" {
+			return nil
+		}
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return nil
+		}
+		importPath := modulePath
+		if rel != "." {
+			importPath = path.Join(modulePath, filepath.ToSlash(rel))
+		}
+		packages = append(packages, pkg{path: importPath, name: name, dir: dir})
+		return nil
+	})
+	return packages
+}
+
+// packageName reads the package clause of the first source file of a directory
+func packageName(fileSet *token.FileSet, dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if !isPackageFile(entry) {
+			continue
+		}
+		file, err := parser.ParseFile(fileSet, filepath.Join(dir, entry.Name()), nil, parser.PackageClauseOnly)
+		if err == nil && file.Name.Name != "documentation" {
+			return file.Name.Name
+		}
+	}
+	return ""
+}
+
+// isPackageFile checks if a directory entry is a non-test Go source file
+func isPackageFile(entry os.DirEntry) bool {
+	name := entry.Name()
+	return !entry.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") &&
+		!strings.HasPrefix(name, ".") && !strings.HasPrefix(name, "_")
+}
+
+// importerPath returns the import path of the package of a file, empty outside of modules
+func importerPath(index *moduleIndex, filePath string) string {
+	goModPath := utils.FindGoMod(filePath)
+	absPath, err := filepath.Abs(filePath)
+	if index.path == "" || goModPath == "" || err != nil {
+		return ""
+	}
+	rel, err := filepath.Rel(filepath.Dir(goModPath), filepath.Dir(absPath))
+	if err != nil || rel == "." {
+		return index.path
+	}
+	return path.Join(index.path, filepath.ToSlash(rel))
+}
+
+// isVisible applies the internal package rule: a path containing an internal element
+// can only be imported from within the tree rooted at the parent of that element
+func isVisible(importPath, importer string) bool {
+	elements := strings.Split(importPath, "/")
+	for i, element := range elements {
+		if element != "internal" {
+			continue
+		}
+		parent := strings.Join(elements[:i], "/")
+		if parent == "" {
+			return false // Internal packages of the standard library
+		}
+		if importer != parent && !strings.HasPrefix(importer, parent+"/") {
+			return false
+		}
+	}
+	return elements[0] != "vendor"
+}
+
+// AssumedName returns the name a package is assumed to have from its import path:
+// its last element without a major version suffix, a go- prefix or a .vN suffix,
+// e.g. yaml for gopkg.in/yaml.v3 and redis for github.com/go-redis/redis/v9
+func AssumedName(importPath string) string {
+	base := path.Base(importPath)
+	if isMajorVersion(base) && path.Dir(importPath) != "." {
+		base = path.Base(path.Dir(importPath))
+	}
+	base = strings.TrimPrefix(base, "go-")
+	if i := strings.IndexFunc(base, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}); i >= 0 {
+		base = base[:i]
+	}
+	return base
+}
+
+// isMajorVersion checks if a path element is a major version suffix such as v2
+func isMajorVersion(element string) bool {
+	if len(element) < 2 || element[0] != 'v' {
+		return false
+	}
+	for _, r := range element[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
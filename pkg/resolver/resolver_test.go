@@ -0,0 +1,122 @@
+package resolver
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/testutil"
+)
+
+// newModule creates a module requiring a dependency found in a fake module cache
+func newModule(t *testing.T) (*Resolver, string) {
+	t.Helper()
+	modCache := t.TempDir()
+	testutil.WriteFiles(t, modCache, map[string]string{
+		"example.com/!dep@v1.2.0/go.mod":              "module example.com/Dep\n",
+		"example.com/!dep@v1.2.0/log/log.go":          "package log\n\nfunc Info() {}\n",
+		"example.com/!dep@v1.2.0/go-kit/kit.go":       "package kit\n\nvar Version = 1\n",
+		"example.com/!dep@v1.2.0/internal/log/log.go": "package log\n\nfunc Info() {}\n",
+		"example.com/!dep@v1.2.0/cmd/tool/main.go":    "package main\n\nfunc main() {}\n",
+	})
+	t.Setenv("GOMODCACHE", modCache)
+
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, map[string]string{
+		"go.mod":                 "module example.com/app\n\ngo 1.22\n\nrequire example.com/Dep v1.2.0\n",
+		"main.go":                "package main\n",
+		"log/log.go":             "package log\n\nfunc Info() {}\nfunc Debug() {}\n",
+		"internal/json/json.go":  "package json\n\nfunc Marshal() {}\n",
+		"api/v2/api.go":          "package api\n\ntype Client struct{}\n",
+		"testdata/log/log.go":    "package log\n\nfunc Info() {}\n",
+		"nested/go.mod":          "module example.com/nested\n",
+		"nested/log/log.go":      "package log\n\nfunc Info() {}\n",
+		"log/log_test.go":        "package log\n\nfunc Test() {}\n",
+		"internal/json/doc.go":   "// Package json encodes values.\npackage json\n",
+		"vendor/x/log/log.go":    "package log\n\nfunc Info() {}\n",
+		".hidden/log/log.go":     "package log\n\nfunc Info() {}\n",
+		"_examples/log/log.go":   "package log\n\nfunc Info() {}\n",
+		"api/v2/api_internal.go": "package api\n\nfunc New() *Client { return nil }\n",
+	})
+	return New(), filepath.Join(root, "main.go")
+}
+
+func TestResolver_Candidates(t *testing.T) {
+	req := require.New(t)
+	r, filePath := newModule(t)
+
+	req.Equal([]string{"example.com/Dep/log", "example.com/app/log", "log"}, r.Candidates(filePath, "log", nil))
+	req.Equal([]string{"example.com/Dep/log", "example.com/app/log"}, r.Candidates(filePath, "log", []string{"Info"}))
+	req.Equal([]string{"example.com/app/log"}, r.Candidates(filePath, "log", []string{"Debug", "Info"}))
+	req.Equal([]string{"example.com/Dep/go-kit"}, r.Candidates(filePath, "kit", []string{"Version"}))
+	req.Equal([]string{"example.com/app/api/v2"}, r.Candidates(filePath, "api", []string{"Client", "New"}))
+	req.Empty(r.Candidates(filePath, "log", []string{"Unknown"}))
+
+	// Internal packages are only visible from their parent tree
+	req.Equal([]string{"encoding/json", "example.com/app/internal/json"}, r.Candidates(filePath, "json", []string{"Marshal"}))
+	req.Equal([]string{"encoding/json"}, r.Candidates(filepath.Join(t.TempDir(), "main.go"), "json", []string{"Marshal"}))
+
+	// A package does not import itself
+	req.Equal([]string{"example.com/Dep/log"}, r.Candidates(filepath.Join(filepath.Dir(filePath), "log", "log.go"), "log", []string{"Info"}))
+}
+
+func TestResolver_PackageName(t *testing.T) {
+	req := require.New(t)
+	r, filePath := newModule(t)
+
+	req.Equal("kit", r.PackageName(filePath, "example.com/Dep/go-kit"))
+	req.Equal("api", r.PackageName(filePath, "example.com/app/api/v2"))
+	req.Equal("json", r.PackageName(filePath, "encoding/json"))
+	req.Equal("unknown", r.PackageName(filePath, "example.com/unknown"))
+
+	req.True(r.Exports(filePath, "example.com/app/log", []string{"Debug"}))
+	req.False(r.Exports(filePath, "example.com/Dep/log", []string{"Debug"}))
+	req.True(r.Exports(filePath, "example.com/unknown", []string{"Debug"}), "packages not on disk are assumed to export the symbols")
+
+	req.Equal(map[string]bool{"Info": true, "Debug": true}, r.ExportedNames(filePath, "example.com/app/log"))
+	req.Nil(r.ExportedNames(filePath, "example.com/unknown"))
+
+	req.Equal("go1.22", r.GoVersion(filePath))
+	req.Empty(r.GoVersion(filepath.Join(t.TempDir(), "main.go")))
+}
+
+func TestResolver_Replace(t *testing.T) {
+	req := require.New(t)
+	t.Setenv("GOMODCACHE", t.TempDir())
+
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, map[string]string{
+		"app/go.mod":         "module example.com/app\n\nrequire example.com/dep v1.0.0\n\nreplace example.com/dep => ../dep\n",
+		"app/main.go":        "package main\n",
+		"dep/go.mod":         "module example.com/dep\n",
+		"dep/trace/trace.go": "package tracing\n\nfunc Start() {}\n",
+	})
+	r := New()
+	filePath := filepath.Join(root, "app", "main.go")
+	req.Equal([]string{"example.com/dep/trace"}, r.Candidates(filePath, "tracing", []string{"Start"}))
+	req.Equal("tracing", r.PackageName(filePath, "example.com/dep/trace"))
+}
+
+func TestAssumedName(t *testing.T) {
+	tests := []struct {
+		importPath string
+		expected   string
+	}{
+		{"fmt", "fmt"},
+		{"encoding/json", "json"},
+		{"math/rand/v2", "rand"},
+		{"gopkg.in/yaml.v3", "yaml"},
+		{"github.com/go-redis/redis/v9", "redis"},
+		{"github.com/go-sql-driver/mysql", "mysql"},
+		{"github.com/mattn/go-sqlite3", "sqlite3"},
+		{"github.com/acme/go-kit-logging", "kit"},
+		{"github.com/acme/platform/v2/api", "api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.importPath, func(t *testing.T) {
+			require.New(t).Equal(tt.expected, AssumedName(tt.importPath))
+		})
+	}
+}
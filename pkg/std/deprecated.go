@@ -0,0 +1,54 @@
+package std
+
+import "go/version"
+
+// Migration replaces a deprecated symbol of the standard library by its modern equivalent
+type Migration struct {
+	Path      string // import path of the deprecated symbol
+	Symbol    string // deprecated symbol
+	NewPath   string // import path of the replacement
+	NewSymbol string // replacement symbol
+	Since     string // first Go release providing the replacement, e.g. go1.16
+}
+
+// Migrations lists the deprecated symbols having a drop-in replacement. Symbols whose
+// replacement behaves differently, such as io/ioutil.ReadDir returning fs.FileInfo
+// instead of fs.DirEntry, are left out.
+var Migrations = []Migration{
+	{"io/ioutil", "Discard", "io", "Discard", "go1.16"},
+	{"io/ioutil", "NopCloser", "io", "NopCloser", "go1.16"},
+	{"io/ioutil", "ReadAll", "io", "ReadAll", "go1.16"},
+	{"io/ioutil", "ReadFile", "os", "ReadFile", "go1.16"},
+	{"io/ioutil", "TempDir", "os", "MkdirTemp", "go1.16"},
+	{"io/ioutil", "TempFile", "os", "CreateTemp", "go1.16"},
+	{"io/ioutil", "WriteFile", "os", "WriteFile", "go1.16"},
+	{"os", "SEEK_CUR", "io", "SeekCurrent", "go1.7"},
+	{"os", "SEEK_END", "io", "SeekEnd", "go1.7"},
+	{"os", "SEEK_SET", "io", "SeekStart", "go1.7"},
+	{"reflect", "Ptr", "reflect", "Pointer", "go1.18"},
+}
+
+// LookupMigration finds the migration of a deprecated symbol whose replacement is
+// available in a Go release, e.g. go1.21. Any release is accepted when it is empty.
+func LookupMigration(importPath, symbol, goVersion string) (Migration, bool) {
+	for _, migration := range Migrations {
+		if migration.Path != importPath || migration.Symbol != symbol {
+			continue
+		}
+		if goVersion != "" && version.Compare(goVersion, migration.Since) < 0 {
+			return Migration{}, false
+		}
+		return migration, true
+	}
+	return Migration{}, false
+}
+
+// HasMigrations checks if an import path has deprecated symbols with a replacement
+func HasMigrations(importPath string) bool {
+	for _, migration := range Migrations {
+		if migration.Path == importPath {
+			return true
+		}
+	}
+	return false
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"go/build"
+	"go/format"
 	"log"
 	"os"
 	"path/filepath"
@@ -171,5 +172,10 @@ func IsStandardPackage(importPath string) bool {
 }
 `
 
-	return os.WriteFile(outputPath, []byte(content), 0644)
+	// Align the entries of the map the way gofmt does
+	formatted, err := format.Source([]byte(content))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, formatted, 0644)
 }
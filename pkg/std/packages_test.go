@@ -41,3 +41,24 @@ func TestStandardPackagesMapNotEmpty(t *testing.T) {
 		req.True(StandardPackages[pkg], "Expected standard package %q not found in StandardPackages map", pkg)
 	}
 }
+
+func TestLookupMigration(t *testing.T) {
+	req := require.New(t)
+
+	migration, ok := LookupMigration("io/ioutil", "ReadFile", "go1.21")
+	req.True(ok)
+	req.Equal("os", migration.NewPath)
+	req.Equal("ReadFile", migration.NewSymbol)
+
+	_, ok = LookupMigration("io/ioutil", "ReadFile", "go1.15")
+	req.False(ok, "replacements newer than the go directive are not used")
+	_, ok = LookupMigration("io/ioutil", "ReadFile", "")
+	req.True(ok)
+	_, ok = LookupMigration("io/ioutil", "ReadDir", "go1.21")
+	req.False(ok, "replacements with another behavior are not listed")
+
+	for _, migration := range Migrations {
+		req.True(IsStandardPackage(migration.Path), migration.Path)
+		req.True(IsStandardPackage(migration.NewPath), migration.NewPath)
+	}
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"go/build"
+	"path/filepath"
+)
+
+// NewBuildContext creates a build context for the given GOOS, GOARCH and build tags,
+// falling back to the defaults of the running toolchain for empty values
+func NewBuildContext(goos, goarch string, tags []string) *build.Context {
+	ctx := build.Default
+	if goos != "" {
+		ctx.GOOS = goos
+	}
+	if goarch != "" {
+		ctx.GOARCH = goarch
+	}
+	ctx.BuildTags = append([]string{}, tags...)
+	return &ctx
+}
+
+// FilterBuildFiles returns the Go files matching the build context, evaluating
+// both the file name suffixes (e.g., _linux_amd64.go) and //go:build constraints.
+// Files whose constraints cannot be read are kept so that processing reports the error.
+func FilterBuildFiles(files []string, ctx *build.Context) []string {
+	var matched []string
+	for _, file := range files {
+		ok, err := ctx.MatchFile(filepath.Dir(file), filepath.Base(file))
+		if err != nil || ok {
+			matched = append(matched, file)
+		}
+	}
+	return matched
+}
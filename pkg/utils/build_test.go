@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterBuildFiles(t *testing.T) {
+	req := require.New(t)
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"common.go":          "package p",
+		"os_linux.go":        "package p",
+		"os_windows.go":      "package p",
+		"arch_arm64.go":      "package p",
+		"net_linux_amd64.go": "package p",
+		"integration.go":     "//go:build integration\n\npackage p",
+		"not_integration.go": "//go:build !integration\n\npackage p",
+		"darwin_tag.go":      "//go:build darwin || (linux && cgo)\n\npackage p",
+	}
+	var paths []string
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		req.NoError(os.WriteFile(path, []byte(content), 0644))
+		paths = append(paths, path)
+	}
+
+	tests := []struct {
+		name     string
+		goos     string
+		goarch   string
+		tags     []string
+		expected []string
+	}{
+		{
+			name:     "linux amd64 without tags",
+			goos:     "linux",
+			goarch:   "amd64",
+			expected: []string{"common.go", "os_linux.go", "net_linux_amd64.go", "not_integration.go"},
+		},
+		{
+			name:     "windows arm64 with integration tag",
+			goos:     "windows",
+			goarch:   "arm64",
+			tags:     []string{"integration"},
+			expected: []string{"common.go", "os_windows.go", "arch_arm64.go", "integration.go"},
+		},
+		{
+			name:     "darwin amd64",
+			goos:     "darwin",
+			goarch:   "amd64",
+			expected: []string{"common.go", "not_integration.go", "darwin_tag.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := require.New(t)
+			ctx := NewBuildContext(tt.goos, tt.goarch, tt.tags)
+			ctx.CgoEnabled = false
+
+			var expected []string
+			for _, name := range tt.expected {
+				expected = append(expected, filepath.Join(tempDir, name))
+			}
+			req.ElementsMatch(expected, FilterBuildFiles(paths, ctx))
+		})
+	}
+}
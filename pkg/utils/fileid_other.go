@@ -0,0 +1,12 @@
+//go:build !unix
+
+package utils
+
+import (
+	"os"
+)
+
+// getFileID identifies a file by its resolved path on platforms without inode numbers
+func getFileID(path string, _ os.FileInfo) (fileID, error) {
+	return getFileIDByPath(path)
+}
@@ -0,0 +1,16 @@
+//go:build unix
+
+package utils
+
+import (
+	"os"
+	"syscall"
+)
+
+// getFileID identifies a file by its device and inode numbers
+func getFileID(path string, info os.FileInfo) (fileID, error) {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, nil
+	}
+	return getFileIDByPath(path)
+}
@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"bytes"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -11,8 +13,84 @@ func IsGoFile(filename string) bool {
 	return strings.HasSuffix(filename, ".go")
 }
 
+// IsSkippedDir checks if a directory should be skipped when looking for Go files
+func IsSkippedDir(name string) bool {
+	return name == "vendor" || name == ".git" || strings.HasPrefix(name, ".")
+}
+
+// MatchGlob checks if a slash-separated path matches a glob pattern, where ** matches
+// any number of path elements and the other elements follow path.Match. Patterns without
+// a slash match the last element of the path, e.g. *_test.go.
+func MatchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+	return matchElements(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchElements matches the elements of a path against the elements of a pattern
+func matchElements(pattern, elements []string) bool {
+	if len(pattern) == 0 {
+		return len(elements) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(elements); i++ {
+			if matchElements(pattern[1:], elements[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(elements) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], elements[0])
+	return ok && matchElements(pattern[1:], elements[1:])
+}
+
+// FindOptions controls how directories are traversed when finding Go files
+type FindOptions struct {
+	FollowSymlinks bool // descend into symlinked directories and include symlinked files
+}
+
+// fileID uniquely identifies a file or directory independently of the path used to reach it
+type fileID struct {
+	dev  uint64
+	ino  uint64
+	path string // resolved path, used where device and inode numbers are unavailable
+}
+
+// resolvePath returns the absolute path with symlinks resolved
+func resolvePath(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(absPath)
+}
+
+// getFileIDByPath identifies a file by its absolute path with symlinks resolved
+func getFileIDByPath(path string) (fileID, error) {
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return fileID{}, err
+	}
+	return fileID{path: resolved}, nil
+}
+
 // FindGoFiles recursively finds all Go source files in a directory
 func FindGoFiles(root string) ([]string, error) {
+	return FindGoFilesWithOptions(root, FindOptions{})
+}
+
+// FindGoFilesWithOptions recursively finds all Go source files in a directory
+// using the given traversal options
+func FindGoFilesWithOptions(root string, opts FindOptions) ([]string, error) {
+	if opts.FollowSymlinks {
+		return findGoFilesFollowingSymlinks(root)
+	}
+
 	var goFiles []string
 
 	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
@@ -22,8 +100,7 @@ func FindGoFiles(root string) ([]string, error) {
 
 		// Skip vendor directories and hidden directories (but not the root directory)
 		if info.IsDir() && path != root {
-			name := filepath.Base(path)
-			if name == "vendor" || name == ".git" || strings.HasPrefix(name, ".") {
+			if IsSkippedDir(filepath.Base(path)) {
 				return filepath.SkipDir
 			}
 			return nil
@@ -39,6 +116,83 @@ func FindGoFiles(root string) ([]string, error) {
 	return goFiles, err
 }
 
+// findGoFilesFollowingSymlinks recursively finds all Go source files in a directory,
+// following symlinks. Directories are identified by device and inode so that symlink
+// cycles are detected, and files reachable by multiple paths are only returned once.
+func findGoFilesFollowingSymlinks(root string) ([]string, error) {
+	var goFiles []string
+	visitedDirs := make(map[fileID]bool)
+	seenFiles := make(map[fileID]bool)
+
+	addFile := func(path string, info os.FileInfo) error {
+		if !IsGoFile(filepath.Base(path)) || !info.Mode().IsRegular() {
+			return nil
+		}
+		id, err := getFileID(path, info)
+		if err != nil {
+			return err
+		}
+		if !seenFiles[id] {
+			seenFiles[id] = true
+			goFiles = append(goFiles, path)
+		}
+		return nil
+	}
+
+	var walk func(dir string, info os.FileInfo) error
+	walk = func(dir string, info os.FileInfo) error {
+		id, err := getFileID(dir, info)
+		if err != nil {
+			return err
+		}
+		if visitedDirs[id] {
+			return nil // Symlink cycle or directory already reached through another path
+		}
+		visitedDirs[id] = true
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			info, err := os.Stat(path)
+			if err != nil {
+				if entry.Type()&os.ModeSymlink != 0 && os.IsNotExist(err) {
+					continue // Skip broken symlinks
+				}
+				return err
+			}
+
+			if info.IsDir() {
+				if IsSkippedDir(entry.Name()) {
+					continue
+				}
+				if err := walk(path, info); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := addFile(path, info); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		err = addFile(root, info)
+	} else {
+		err = walk(root, info)
+	}
+	return goFiles, err
+}
+
 // IsDirectory checks if the given path is a directory
 func IsDirectory(path string) (bool, error) {
 	info, err := os.Stat(path)
@@ -47,3 +201,131 @@ func IsDirectory(path string) (bool, error) {
 	}
 	return info.IsDir(), nil
 }
+
+// WriteFileAtomic replaces the content of an existing file by writing a temporary file in
+// the same directory and renaming it over the original, so that a crash never leaves a
+// partially written file. The mode of the file, including its setuid, setgid and sticky
+// bits, and its owner, when permitted, are preserved and symlinks are written through.
+// It returns false without writing when the content is already identical.
+func WriteFileAtomic(path string, data []byte) (bool, error) {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return false, err
+	}
+	// Leave the file, its modification time and build caches untouched
+	if current, err := os.ReadFile(target); err == nil && bytes.Equal(current, data) {
+		return false, nil
+	}
+
+	tmpPath, err := writeTemp(target, data)
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmpPath) // No-op once renamed
+
+	// The owner is set first, changing it clears the setuid and setgid bits
+	if err := preserveOwner(tmpPath, info); err != nil {
+		return false, err
+	}
+	if err := os.Chmod(tmpPath, info.Mode()&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky)); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmpPath, target); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateFileAtomic creates a file with the given mode so that it is never seen partially
+// written, except on file systems without hard links. An existing file is never replaced:
+// it returns false without writing instead.
+func CreateFileAtomic(path string, data []byte, perm os.FileMode) (bool, error) {
+	tmpPath, err := writeTemp(path, data)
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmpPath)
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return false, err
+	}
+
+	// Unlike a rename, a link fails when the file exists
+	err = os.Link(tmpPath, path)
+	switch {
+	case err == nil:
+		return true, nil
+	case os.IsExist(err):
+		return false, nil
+	default:
+		// Hard links are not supported by every file system, such as some network and
+		// FUSE file systems, the file is created exclusively instead
+		return createExclusive(path, data, perm)
+	}
+}
+
+// createExclusive creates a file with the given mode. It returns false without writing
+// when the file exists.
+func createExclusive(path string, data []byte, perm os.FileMode) (bool, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		if os.IsExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(path, perm) // The mode given to OpenFile is subject to the umask
+	}
+	if err != nil {
+		os.Remove(path)
+		return false, err
+	}
+	return true, nil
+}
+
+// writeTemp writes data to a temporary file next to path, synced to disk, and returns its path
+func writeTemp(path string, data []byte) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".gig-*")
+	if err != nil {
+		return "", err
+	}
+	tmpPath := tmp.Name()
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	return tmpPath, nil
+}
+
+// preserveOwner gives a file the owner of another one. Users that may not change the
+// owner keep theirs, as when they write the file in place.
+func preserveOwner(path string, info os.FileInfo) error {
+	uid, gid, ok := fileOwner(info)
+	if !ok {
+		return nil
+	}
+	if err := os.Chown(path, uid, gid); err != nil && !os.IsPermission(err) {
+		return err
+	}
+	return nil
+}
@@ -3,7 +3,9 @@ package utils
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -75,6 +77,31 @@ func TestIsGoFile(t *testing.T) {
 	}
 }
 
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*_test.go", "pkg/api/api_test.go", true},
+		{"*_test.go", "pkg/api/api.go", false},
+		{"cmd/**", "cmd/gig/main.go", true},
+		{"cmd/**", "pkg/cmd/root.go", false},
+		{"**/internal/**", "internal/a.go", true},
+		{"**/internal/**", "pkg/internal/x/a.go", true},
+		{"**/internal/*.go", "pkg/internal/x/a.go", false},
+		{"pkg/*/a.go", "pkg/api/a.go", true},
+		{"pkg/*/a.go", "pkg/api/v1/a.go", false},
+		{"**", "a.go", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			require.New(t).Equal(tt.expected, MatchGlob(tt.pattern, tt.name))
+		})
+	}
+}
+
 func TestIsDirectory(t *testing.T) {
 	req := require.New(t)
 	// Create a temporary directory for testing
@@ -245,3 +272,217 @@ func TestFindGoFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestFindGoFilesWithOptions_FollowSymlinks(t *testing.T) {
+	req := require.New(t)
+	tempDir := t.TempDir()
+	root := filepath.Join(tempDir, "root")
+	external := filepath.Join(tempDir, "external")
+
+	for _, dir := range []string{"root/pkg/a", "root/.hidden", "external/lib"} {
+		req.NoError(os.MkdirAll(filepath.Join(tempDir, dir), 0755))
+	}
+	files := map[string]string{
+		"root/main.go":         "package main",
+		"root/pkg/a/a.go":      "package a",
+		"root/.hidden/h.go":    "package h",
+		"external/lib/lib.go":  "package lib",
+		"external/lib/util.go": "package lib",
+	}
+	for filePath, content := range files {
+		req.NoError(os.WriteFile(filepath.Join(tempDir, filePath), []byte(content), 0644))
+	}
+
+	symlinks := map[string]string{
+		"root/lib":           external,                         // Only path to external sources
+		"root/pkg/lib-again": filepath.Join(external, "lib"),   // Same directory through another path
+		"root/pkg/a/loop":    filepath.Join(root, "pkg"),       // Cycle
+		"root/alias.go":      filepath.Join(root, "main.go"),   // Same file through another path
+		"root/broken":        filepath.Join(tempDir, "absent"), // Broken symlink
+		"root/hidden-link":   filepath.Join(root, ".hidden"),   // Symlink to a hidden directory
+	}
+	for link, target := range symlinks {
+		if err := os.Symlink(target, filepath.Join(tempDir, link)); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	t.Run("without following symlinks", func(t *testing.T) {
+		req := require.New(t)
+		result, err := FindGoFilesWithOptions(root, FindOptions{})
+		req.NoError(err)
+		req.ElementsMatch([]string{
+			filepath.Join(root, "alias.go"),
+			filepath.Join(root, "main.go"),
+			filepath.Join(root, "pkg/a/a.go"),
+		}, result)
+	})
+
+	t.Run("following symlinks", func(t *testing.T) {
+		req := require.New(t)
+		result, err := FindGoFilesWithOptions(root, FindOptions{FollowSymlinks: true})
+		req.NoError(err)
+
+		req.Len(result, 5, "each file should be found once: %v", result)
+		req.Contains(result, filepath.Join(root, "pkg/a/a.go"))
+		req.Contains(result, filepath.Join(root, "hidden-link/h.go"))
+
+		// main.go and alias.go point to the same file, lib.go and util.go are
+		// reachable through both lib and pkg/lib-again
+		req.Equal(1, countWithSuffix(result, "main.go")+countWithSuffix(result, "alias.go"))
+		req.Equal(1, countWithSuffix(result, "/lib.go"))
+		req.Equal(1, countWithSuffix(result, "/util.go"))
+	})
+
+	t.Run("symlinked root", func(t *testing.T) {
+		req := require.New(t)
+		result, err := FindGoFilesWithOptions(filepath.Join(root, "lib"), FindOptions{FollowSymlinks: true})
+		req.NoError(err)
+		req.ElementsMatch([]string{
+			filepath.Join(root, "lib/lib/lib.go"),
+			filepath.Join(root, "lib/lib/util.go"),
+		}, result)
+	})
+
+	t.Run("non-existent root", func(t *testing.T) {
+		_, err := FindGoFilesWithOptions(filepath.Join(tempDir, "absent"), FindOptions{FollowSymlinks: true})
+		require.Error(t, err)
+	})
+}
+
+func countWithSuffix(paths []string, suffix string) int {
+	count := 0
+	for _, path := range paths {
+		if strings.HasSuffix(path, suffix) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	req := require.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "script.go")
+	req.NoError(os.WriteFile(path, []byte("package main\n"), 0640))
+	req.NoError(os.Chmod(path, 0750))
+
+	written, err := WriteFileAtomic(path, []byte("package main\n\nfunc main() {}\n"))
+	req.NoError(err)
+	req.True(written)
+	content, err := os.ReadFile(path)
+	req.NoError(err)
+	req.Equal("package main\n\nfunc main() {}\n", string(content))
+	info, err := os.Stat(path)
+	req.NoError(err)
+	req.Equal(os.FileMode(0750), info.Mode().Perm(), "mode is preserved")
+
+	// Identical content is not written
+	past := info.ModTime().Add(-time.Hour)
+	req.NoError(os.Chtimes(path, past, past))
+	written, err = WriteFileAtomic(path, []byte("package main\n\nfunc main() {}\n"))
+	req.NoError(err)
+	req.False(written)
+	info, err = os.Stat(path)
+	req.NoError(err)
+	req.True(info.ModTime().Equal(past), "modification time is untouched")
+
+	// Symlinks are written through
+	link := filepath.Join(dir, "link.go")
+	req.NoError(os.Symlink(path, link))
+	written, err = WriteFileAtomic(link, []byte("package other\n"))
+	req.NoError(err)
+	req.True(written)
+	linkInfo, err := os.Lstat(link)
+	req.NoError(err)
+	req.NotZero(linkInfo.Mode() & os.ModeSymlink)
+	content, err = os.ReadFile(path)
+	req.NoError(err)
+	req.Equal("package other\n", string(content))
+
+	// No temporary file is left behind
+	entries, err := os.ReadDir(dir)
+	req.NoError(err)
+	req.Len(entries, 2)
+
+	// The setuid, setgid and sticky bits are preserved
+	req.NoError(os.Chmod(path, 0750|os.ModeSetuid|os.ModeSetgid|os.ModeSticky))
+	written, err = WriteFileAtomic(path, []byte("package main\n"))
+	req.NoError(err)
+	req.True(written)
+	info, err = os.Stat(path)
+	req.NoError(err)
+	req.Equal(0750|os.ModeSetuid|os.ModeSetgid|os.ModeSticky, info.Mode())
+
+	_, err = WriteFileAtomic(filepath.Join(dir, "missing.go"), []byte("package main\n"))
+	req.Error(err)
+}
+
+func TestWriteFileAtomic_Owner(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("changing the owner of the file requires root")
+	}
+	req := require.New(t)
+	path := filepath.Join(t.TempDir(), "main.go")
+	req.NoError(os.WriteFile(path, []byte("package main\n"), 0644))
+	req.NoError(os.Chown(path, 65534, 65534))
+
+	written, err := WriteFileAtomic(path, []byte("package other\n"))
+	req.NoError(err)
+	req.True(written)
+	info, err := os.Stat(path)
+	req.NoError(err)
+	uid, gid, ok := fileOwner(info)
+	req.True(ok)
+	req.Equal(65534, uid)
+	req.Equal(65534, gid)
+}
+
+func TestCreateFileAtomic(t *testing.T) {
+	req := require.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go.orig")
+
+	created, err := CreateFileAtomic(path, []byte("package main\n"), 0640)
+	req.NoError(err)
+	req.True(created)
+	info, err := os.Stat(path)
+	req.NoError(err)
+	req.Equal(os.FileMode(0640), info.Mode().Perm())
+
+	// An existing file is kept
+	created, err = CreateFileAtomic(path, []byte("package other\n"), 0640)
+	req.NoError(err)
+	req.False(created)
+	content, err := os.ReadFile(path)
+	req.NoError(err)
+	req.Equal("package main\n", string(content))
+
+	// No temporary file is left behind
+	entries, err := os.ReadDir(dir)
+	req.NoError(err)
+	req.Len(entries, 1)
+}
+
+func TestCreateExclusive(t *testing.T) {
+	req := require.New(t)
+	path := filepath.Join(t.TempDir(), "main.go.orig")
+
+	created, err := createExclusive(path, []byte("package main\n"), 0640)
+	req.NoError(err)
+	req.True(created)
+	info, err := os.Stat(path)
+	req.NoError(err)
+	req.Equal(os.FileMode(0640), info.Mode().Perm())
+
+	// An existing file is kept
+	created, err = createExclusive(path, []byte("package other\n"), 0640)
+	req.NoError(err)
+	req.False(created)
+	content, err := os.ReadFile(path)
+	req.NoError(err)
+	req.Equal("package main\n", string(content))
+
+	_, err = createExclusive(filepath.Join(path, "missing", "a.go"), nil, 0640)
+	req.Error(err)
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/siyuan-infoblox/go-imports-group/pkg/errors"
+)
+
+// FindChangedGoFiles finds the Go files under root that differ from the given git ref,
+// including changes in the working tree that are not yet committed and untracked files
+// that are not ignored
+func FindChangedGoFiles(root, ref string) ([]string, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("%s: %q", errors.ErrMsgInvalidGitRef, ref)
+	}
+	return findGitGoFiles(root,
+		[]string{"diff", "--name-only", "-z", "--diff-filter=ACMR", ref, "--"},
+		[]string{"ls-files", "--others", "--exclude-standard", "-z"},
+	)
+}
+
+// FindStagedGoFiles finds the Go files under root that are staged in the git index
+func FindStagedGoFiles(root string) ([]string, error) {
+	return findGitGoFiles(root, []string{"diff", "--cached", "--name-only", "-z", "--diff-filter=ACMR", "--"})
+}
+
+// FindUnstagedGoFiles finds the Go files under root whose working tree content differs from
+// the git index, such as partially staged files
+func FindUnstagedGoFiles(root string) ([]string, error) {
+	return findGitGoFiles(root, []string{"diff", "--name-only", "-z", "--diff-filter=ACMR", "--"})
+}
+
+// GitTopLevel returns the root directory of the git working tree containing dir
+func GitTopLevel(dir string) (string, error) {
+	out, err := runGit(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GitHooksDir returns the directory of the hooks of the git repository containing dir,
+// honoring core.hooksPath
+func GitHooksDir(dir string) (string, error) {
+	out, err := runGit(dir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	hooksDir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(dir, hooksDir)
+	}
+	return hooksDir, nil
+}
+
+// GitAdd stages files in the git index
+func GitAdd(dir string, files ...string) error {
+	_, err := runGit(dir, append([]string{"add", "--"}, files...)...)
+	return err
+}
+
+// findGitGoFiles runs git commands listing repository relative paths and
+// returns the Go files among them that are located under root
+func findGitGoFiles(root string, commands ...[]string) ([]string, error) {
+	isDir, err := IsDirectory(root)
+	if err != nil {
+		return nil, err
+	}
+	dir := root
+	if !isDir {
+		dir = filepath.Dir(root)
+	}
+
+	topLevel, err := GitTopLevel(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []byte
+	for _, args := range commands {
+		names, err := runGit(topLevel, args...)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, names...)
+	}
+
+	absRoot, err := resolvePath(root)
+	if err != nil {
+		return nil, err
+	}
+	absTopLevel, err := resolvePath(topLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	var goFiles []string
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name == "" || !IsGoFile(name) {
+			continue
+		}
+
+		rel, err := filepath.Rel(absRoot, filepath.Join(absTopLevel, filepath.FromSlash(name)))
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue // Outside of root
+		}
+
+		if !isDir {
+			if rel == "." {
+				goFiles = append(goFiles, root)
+			}
+			continue
+		}
+
+		// Apply the same directory rules as FindGoFiles
+		if hasSkippedDir(rel) {
+			continue
+		}
+		goFiles = append(goFiles, filepath.Join(root, rel))
+	}
+
+	return goFiles, nil
+}
+
+// hasSkippedDir checks if any directory component of a relative file path is skipped
+func hasSkippedDir(rel string) bool {
+	dirs := strings.Split(filepath.ToSlash(filepath.Dir(rel)), "/")
+	for _, name := range dirs {
+		if name != "." && IsSkippedDir(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// runGit runs a git command in the given directory and returns its standard output
+func runGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s: git %s: %w: %s", errors.ErrMsgGitCommandFailed, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
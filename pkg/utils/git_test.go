@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// initGitRepo creates a throwaway git repository with an initial commit
+func initGitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	req := require.New(t)
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	repoDir := t.TempDir()
+	writeRepoFiles(t, repoDir, files)
+
+	gitCommands := [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"config", "commit.gpgsign", "false"},
+		{"add", "-A"},
+		{"commit", "-q", "-m", "initial"},
+	}
+	for _, args := range gitCommands {
+		_, err := runGit(repoDir, args...)
+		req.NoError(err, "git %v", args)
+	}
+	return repoDir
+}
+
+func writeRepoFiles(t *testing.T, repoDir string, files map[string]string) {
+	t.Helper()
+	req := require.New(t)
+	for filePath, content := range files {
+		fullPath := filepath.Join(repoDir, filePath)
+		req.NoError(os.MkdirAll(filepath.Dir(fullPath), 0755))
+		req.NoError(os.WriteFile(fullPath, []byte(content), 0644))
+	}
+}
+
+func TestFindStagedGoFiles(t *testing.T) {
+	req := require.New(t)
+	repoDir := initGitRepo(t, map[string]string{
+		"main.go":         "package main",
+		"pkg/a/a.go":      "package a",
+		"pkg/b/b.go":      "package b",
+		"vendor/v/v.go":   "package v",
+		"docs/README.md":  "# docs",
+		"pkg/c/c_test.go": "package c",
+	})
+
+	writeRepoFiles(t, repoDir, map[string]string{
+		"pkg/a/a.go":      "package a\n\nvar A = 1",
+		"pkg/b/b.go":      "package b\n\nvar B = 1", // Modified but not staged
+		"pkg/d/d.go":      "package d",
+		"vendor/v/v.go":   "package v\n\nvar V = 1",
+		"docs/README.md":  "# changed docs",
+		"pkg/c/c_test.go": "package c\n\nvar C = 1",
+	})
+	_, err := runGit(repoDir, "add", "pkg/a/a.go", "pkg/d/d.go", "vendor/v/v.go", "docs/README.md", "pkg/c/c_test.go")
+	req.NoError(err)
+
+	t.Run("staged files in repository", func(t *testing.T) {
+		result, err := FindStagedGoFiles(repoDir)
+		req.NoError(err)
+		req.ElementsMatch([]string{
+			filepath.Join(repoDir, "pkg/a/a.go"),
+			filepath.Join(repoDir, "pkg/c/c_test.go"),
+			filepath.Join(repoDir, "pkg/d/d.go"),
+		}, result)
+	})
+
+	t.Run("staged files in subdirectory", func(t *testing.T) {
+		result, err := FindStagedGoFiles(filepath.Join(repoDir, "pkg/a"))
+		req.NoError(err)
+		req.Equal([]string{filepath.Join(repoDir, "pkg/a/a.go")}, result)
+	})
+
+	t.Run("single staged file", func(t *testing.T) {
+		result, err := FindStagedGoFiles(filepath.Join(repoDir, "pkg/d/d.go"))
+		req.NoError(err)
+		req.Equal([]string{filepath.Join(repoDir, "pkg/d/d.go")}, result)
+	})
+
+	t.Run("single unstaged file", func(t *testing.T) {
+		result, err := FindStagedGoFiles(filepath.Join(repoDir, "pkg/b/b.go"))
+		req.NoError(err)
+		req.Empty(result)
+	})
+}
+
+func TestFindChangedGoFiles(t *testing.T) {
+	req := require.New(t)
+	repoDir := initGitRepo(t, map[string]string{
+		"main.go":    "package main",
+		"pkg/a/a.go": "package a",
+		"pkg/b/b.go": "package b",
+	})
+
+	writeRepoFiles(t, repoDir, map[string]string{
+		"pkg/a/a.go": "package a\n\nvar A = 1",
+	})
+	_, err := runGit(repoDir, "commit", "-q", "-am", "change a")
+	req.NoError(err)
+
+	writeRepoFiles(t, repoDir, map[string]string{
+		"pkg/b/b.go":      "package b\n\nvar B = 1", // Uncommitted change
+		"pkg/c/c.go":      "package c",              // Untracked file
+		"pkg/d/d.go":      "package d",              // Ignored file
+		".gitignore":      "pkg/d/\n",
+		"vendor/v/v.go":   "package v",
+		"pkg/c/README.md": "# c",
+	})
+	req.NoError(os.Remove(filepath.Join(repoDir, "main.go"))) // Deleted files are ignored
+
+	t.Run("changed since previous commit", func(t *testing.T) {
+		result, err := FindChangedGoFiles(repoDir, "HEAD~1")
+		req.NoError(err)
+		req.ElementsMatch([]string{
+			filepath.Join(repoDir, "pkg/a/a.go"),
+			filepath.Join(repoDir, "pkg/b/b.go"),
+			filepath.Join(repoDir, "pkg/c/c.go"),
+		}, result)
+	})
+
+	t.Run("changed since HEAD", func(t *testing.T) {
+		result, err := FindChangedGoFiles(repoDir, "HEAD")
+		req.NoError(err)
+		req.ElementsMatch([]string{
+			filepath.Join(repoDir, "pkg/b/b.go"),
+			filepath.Join(repoDir, "pkg/c/c.go"),
+		}, result)
+	})
+
+	t.Run("single untracked file", func(t *testing.T) {
+		result, err := FindChangedGoFiles(filepath.Join(repoDir, "pkg/c/c.go"), "HEAD")
+		req.NoError(err)
+		req.Equal([]string{filepath.Join(repoDir, "pkg/c/c.go")}, result)
+	})
+
+	t.Run("unknown ref", func(t *testing.T) {
+		_, err := FindChangedGoFiles(repoDir, "does-not-exist")
+		req.Error(err)
+	})
+
+	t.Run("option-like ref", func(t *testing.T) {
+		_, err := FindChangedGoFiles(repoDir, "--output=/tmp/x")
+		req.Error(err)
+	})
+
+	t.Run("not a git repository", func(t *testing.T) {
+		_, err := FindChangedGoFiles(t.TempDir(), "HEAD")
+		req.Error(err)
+	})
+}
+
+func TestFindUnstagedGoFiles(t *testing.T) {
+	req := require.New(t)
+	repoDir := initGitRepo(t, map[string]string{
+		"pkg/a/a.go": "package a",
+		"pkg/b/b.go": "package b",
+	})
+
+	writeRepoFiles(t, repoDir, map[string]string{
+		"pkg/a/a.go": "package a\n\nvar A = 1",
+		"pkg/b/b.go": "package b\n\nvar B = 1",
+	})
+	req.NoError(GitAdd(repoDir, "pkg/a/a.go"))
+
+	result, err := FindUnstagedGoFiles(repoDir)
+	req.NoError(err)
+	req.Equal([]string{filepath.Join(repoDir, "pkg/b/b.go")}, result)
+}
+
+func TestGitHooksDir(t *testing.T) {
+	req := require.New(t)
+	repoDir := initGitRepo(t, map[string]string{"main.go": "package main"})
+
+	hooksDir, err := GitHooksDir(repoDir)
+	req.NoError(err)
+	req.Equal(filepath.Join(repoDir, ".git", "hooks"), hooksDir)
+
+	_, err = runGit(repoDir, "config", "core.hooksPath", "githooks")
+	req.NoError(err)
+	hooksDir, err = GitHooksDir(repoDir)
+	req.NoError(err)
+	req.Equal(filepath.Join(repoDir, "githooks"), hooksDir)
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -33,12 +34,8 @@ func GetProjectModule(filePath string) string {
 		goModPath := dir + "/go.mod"
 
 		if content, err := os.ReadFile(goModPath); err == nil {
-			lines := strings.Split(string(content), "\n")
-			for _, line := range lines {
-				if strings.HasPrefix(line, "module ") {
-					module := strings.TrimSpace(strings.TrimPrefix(line, "module"))
-					return module
-				}
+			if module := ModulePath(content); module != "" {
+				return module
 			}
 		}
 	}
@@ -56,3 +53,35 @@ func GetProjectModule(filePath string) string {
 	}
 	return ""
 }
+
+// ModulePath extracts the module path from the content of a go.mod file
+func ModulePath(goMod []byte) string {
+	for _, line := range strings.Split(string(goMod), "\n") {
+		if strings.HasPrefix(line, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "module"))
+		}
+	}
+	return ""
+}
+
+// FindGoMod returns the path of the go.mod file closest to a file, empty when there is none
+func FindGoMod(filePath string) string {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return ""
+	}
+
+	dir := filepath.Dir(absPath)
+	for {
+		goModPath := filepath.Join(dir, "go.mod")
+		if info, err := os.Stat(goModPath); err == nil && !info.IsDir() {
+			return goModPath
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
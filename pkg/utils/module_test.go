@@ -50,3 +50,17 @@ func TestUtils_GetProjectModule_fallbacks(t *testing.T) {
 	result = GetProjectModule(srcPath)
 	req.Equal("github.com/user/project", result, "Expected correct project module from src path")
 }
+
+func TestUtils_FindGoMod(t *testing.T) {
+	req := require.New(t)
+	tempDir := t.TempDir()
+	goModPath := filepath.Join(tempDir, "go.mod")
+	req.NoError(os.WriteFile(goModPath, []byte("module github.com/test/project\n\ngo 1.22\n"), 0644))
+	subDir := filepath.Join(tempDir, "internal", "pkg")
+	req.NoError(os.MkdirAll(subDir, 0755))
+
+	req.Equal(goModPath, FindGoMod(filepath.Join(subDir, "test.go")))
+	req.Equal(goModPath, FindGoMod(filepath.Join(tempDir, "main.go")))
+	req.Equal("github.com/test/project", ModulePath([]byte("// comment\nmodule github.com/test/project\n")))
+	req.Empty(ModulePath([]byte("go 1.22\n")))
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package utils
+
+import (
+	"os"
+)
+
+// fileOwner reports no owner on platforms without numeric user and group IDs
+func fileOwner(_ os.FileInfo) (int, int, bool) {
+	return 0, 0, false
+}